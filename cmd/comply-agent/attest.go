@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/in-toto/go-witness/dsse"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// runAttest signs a manual attestation and submits it to a running agent, or writes the
+// signed envelope to a file for later submission with --envelope.
+func runAttest(ctx context.Context, args []string) error {
+	var attestation claims.ManualAttestation
+	var signingKey, agentURL, output, envelopePath, validFrom string
+	var validFor time.Duration
+	fs := flag.NewFlagSet("attest", flag.ExitOnError)
+	fs.StringVar(&attestation.CatalogID, "catalog", "", "Catalog ID of the attested requirement")
+	fs.StringVar(&attestation.RequirementID, "requirement", "", "ID of the attested requirement")
+	fs.StringVar(&attestation.ResourceRef, "resource", "", "Resource the attestation covers, e.g. a team, system, or procedure")
+	fs.StringVar(&attestation.Tenant, "tenant", "", "Tenant the attestation is made for, sent to --agent-url in the X-Tenant header. Defaults to the tenant of the agent.")
+	fs.StringVar(&attestation.Status, "status", claims.StatusCompliant, "Attested result (COMPLIANT, NOT_COMPLIANT, NOT_APPLICABLE, NEEDS_REVIEW)")
+	fs.StringVar(&attestation.Statement, "statement", "", "Statement describing what was reviewed and found")
+	fs.StringVar(&attestation.Attester.Name, "attester-name", "", "Name of the person attesting")
+	fs.StringVar(&attestation.Attester.Email, "attester-email", "", "Email of the person attesting")
+	fs.Func("artifact", "Supporting artifact as name=uri, optionally name=uri@sha256:<hex>. May be repeated.", func(value string) error {
+		artifact, err := parseArtifact(value)
+		if err != nil {
+			return err
+		}
+		attestation.Artifacts = append(attestation.Artifacts, artifact)
+		return nil
+	})
+	fs.StringVar(&validFrom, "valid-from", "", "Start of the validity period (RFC3339), not in the future. Defaults to now.")
+	fs.DurationVar(&validFor, "valid-for", 90*24*time.Hour, "Length of the validity period")
+	fs.StringVar(&signingKey, "signing-key", "", "Path to the attester's PEM private key")
+	fs.StringVar(&agentURL, "agent-url", "", "URL of a running agent API to submit the attestation to (e.g. http://localhost:8090)")
+	fs.StringVar(&output, "output", "", "Path to write the signed attestation envelope to instead of submitting it")
+	fs.StringVar(&envelopePath, "envelope", "", "Path to a signed attestation envelope to submit")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (agentURL == "") == (output == "") {
+		return errors.New("exactly one of --agent-url or --output is required")
+	}
+
+	var envelope dsse.Envelope
+	if envelopePath != "" {
+		data, err := os.ReadFile(envelopePath)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &envelope); err != nil {
+			return fmt.Errorf("invalid attestation envelope %s: %w", envelopePath, err)
+		}
+	} else {
+		if signingKey == "" {
+			return errors.New("--signing-key is required to sign an attestation")
+		}
+		attestation.ValidFrom = time.Now().UTC()
+		if validFrom != "" {
+			start, err := time.Parse(time.RFC3339, validFrom)
+			if err != nil {
+				return fmt.Errorf("invalid --valid-from: %w", err)
+			}
+			attestation.ValidFrom = start
+		}
+		attestation.ValidUntil = attestation.ValidFrom.Add(validFor)
+		if err := attestation.Validate(time.Now()); err != nil {
+			return err
+		}
+		signer, err := loadSigner(signingKey)
+		if err != nil {
+			return err
+		}
+		envelope, err = claims.SignAttestation(attestation, signer)
+		if err != nil {
+			return fmt.Errorf("failed to sign attestation: %w", err)
+		}
+	}
+
+	if output != "" {
+		data, err := json.MarshalIndent(envelope, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(output, data, 0o644)
+	}
+	claim, err := submitAttestation(ctx, agentURL, attestation.Tenant, envelope)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Stored claim %s for %s %s on resource '%s'\n",
+		claim.ClaimID, claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef)
+	return nil
+}
+
+func parseArtifact(value string) (claims.Artifact, error) {
+	name, uri, ok := strings.Cut(value, "=")
+	if !ok || name == "" || uri == "" {
+		return claims.Artifact{}, fmt.Errorf("invalid artifact %q, expected name=uri", value)
+	}
+	artifact := claims.Artifact{Name: name, URI: uri}
+	if at := strings.LastIndex(uri, "@sha256:"); at >= 0 {
+		artifact.URI, artifact.Digest = uri[:at], uri[at+1:]
+	}
+	return artifact, nil
+}
+
+func submitAttestation(ctx context.Context, agentURL, tenant string, envelope dsse.Envelope) (claims.ConformanceClaim, error) {
+	endpoint, err := url.JoinPath(agentURL, "v1", "attestations")
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setTenant(req, tenant)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return claims.ConformanceClaim{}, fmt.Errorf("agent returned %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	var claim claims.ConformanceClaim
+	if err := json.NewDecoder(resp.Body).Decode(&claim); err != nil {
+		return claims.ConformanceClaim{}, fmt.Errorf("error decoding claim: %w", err)
+	}
+	return claim, nil
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/coverage"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// runCoverage reports the evidence coverage of each catalog requirement. Evidence is read
+// from a running agent when --agent-url is set; otherwise only mapping coverage is reported.
+func runCoverage(ctx context.Context, args []string) error {
+	var catalogID, agentURL, output, tenant string
+	var mappings mappingFlags
+	fs := flag.NewFlagSet("coverage", flag.ExitOnError)
+	fs.StringVar(&catalogID, "catalog", "", "Catalog ID to report on. Defaults to every loaded catalog.")
+	fs.StringVar(&agentURL, "agent-url", "", "URL of a running agent API to read evidence coverage from (e.g. http://localhost:8090)")
+	fs.StringVar(&output, "output", "table", "Output format (table, json)")
+	fs.StringVar(&tenant, "tenant", "", "Tenant to read evidence coverage for with --agent-url, sent in the X-Tenant header. Defaults to every tenant, which the agent only serves with --api-unscoped.")
+	mappings.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var reports []coverage.Report
+	var err error
+	if agentURL != "" {
+		reports, err = fetchCoverage(ctx, agentURL, catalogID, tenant)
+	} else {
+		reports, err = localCoverage(mappings, catalogID)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	case "table":
+		return printCoverage(os.Stdout, reports)
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+}
+
+func localCoverage(mappings mappingFlags, catalogID string) ([]coverage.Report, error) {
+	paths, err := mappings.paths()
+	if err != nil {
+		return nil, err
+	}
+	ruleset, err := mapping.Load(paths)
+	if err != nil {
+		return nil, err
+	}
+	if catalogID == "" {
+		return coverage.NewReports(ruleset, nil)
+	}
+	report, err := coverage.NewReport(ruleset, catalogID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []coverage.Report{report}, nil
+}
+
+func fetchCoverage(ctx context.Context, agentURL, catalogID, tenant string) ([]coverage.Report, error) {
+	endpoint, err := url.JoinPath(agentURL, "v1", "coverage")
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	if catalogID != "" {
+		params.Set("catalog", catalogID)
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	setTenant(req, tenant)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("agent returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var reports []coverage.Report
+	if err := json.NewDecoder(resp.Body).Decode(&reports); err != nil {
+		return nil, fmt.Errorf("error decoding coverage report: %w", err)
+	}
+	return reports, nil
+}
+
+func printCoverage(w io.Writer, reports []coverage.Report) error {
+	for _, report := range reports {
+		fmt.Fprintf(w, "Catalog %s (ruleset %s)\n", report.CatalogID, report.ConfigRevision)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "REQUIREMENT\tCONTROL\tMETHODS\tSOURCES\tRESOURCES\tLAST EVIDENCE\tSTALE")
+		for _, requirement := range report.Requirements {
+			methods := make([]string, 0, len(requirement.Methods))
+			for _, method := range requirement.Methods {
+				if method.Source != "" {
+					methods = append(methods, method.Source+"/"+method.PolicyID)
+				} else {
+					methods = append(methods, method.PolicyID)
+				}
+			}
+			lastEvidence := "-"
+			if requirement.LastEvidence != nil {
+				lastEvidence = requirement.LastEvidence.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+				requirement.RequirementID,
+				requirement.ControlID,
+				orDash(strings.Join(methods, ",")),
+				orDash(strings.Join(requirement.Sources, ",")),
+				requirement.Resources,
+				lastEvidence,
+				orDash(strings.Join(requirement.StaleSources, ",")),
+			)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Unmapped requirements: %s\n", orDash(strings.Join(report.Unmapped, ",")))
+		fmt.Fprintf(w, "Requirements without evidence: %s\n", orDash(strings.Join(report.Unevidenced, ",")))
+		fmt.Fprintf(w, "Requirements with stale evidence: %s\n\n", orDash(strings.Join(report.Stale, ",")))
+	}
+	return nil
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
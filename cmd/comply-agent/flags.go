@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/agent"
+	"github.com/jpower432/shiny-journey/processor/api"
+	"github.com/jpower432/shiny-journey/processor/claims/backends/fsevidence"
+	"github.com/jpower432/shiny-journey/processor/claims/backends/ocievidence"
+	"github.com/jpower432/shiny-journey/processor/claims/backends/s3evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// mappingFlags configures the catalogs, plans, and mappings shared by the agent and commands.
+type mappingFlags struct {
+	catalogs             string
+	plans                string
+	tenantPlans          string
+	mappingRules         string
+	componentDefinitions string
+	crosswalks           string
+	c2pPolicy            string
+	c2pConfig            string
+	c2pEvalDir           string
+}
+
+func (m *mappingFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&m.catalogs, "catalogs", "", "Comma-separated catalog files used to validate mappings")
+	fs.StringVar(&m.plans, "plans", "", "Comma-separated assessment plan files used to map evidence to requirements")
+	fs.StringVar(&m.tenantPlans, "tenant-plans", "", "Comma-separated tenant=file assessment plans that only map evidence from that tenant (e.g. team-a=plans/team-a.yaml)")
+	fs.StringVar(&m.mappingRules, "mapping-rules", "", "Comma-separated mapping rule files used to map evidence to requirements")
+	fs.StringVar(&m.componentDefinitions, "component-definitions", "", "Comma-separated OSCAL component definition files used to map policy rules to controls")
+	fs.StringVar(&m.crosswalks, "crosswalks", "", "Comma-separated crosswalk files mapping requirements to other frameworks")
+	fs.StringVar(&m.c2pPolicy, "c2p-policy", "", "C2P policy file binding catalogs to services and plugins (e.g. docs/policy.yaml)")
+	fs.StringVar(&m.c2pConfig, "c2p-config", "", "C2P plugin configuration file (e.g. docs/c2p-config.yaml)")
+	fs.StringVar(&m.c2pEvalDir, "c2p-eval-dir", "docs/evals", "Directory containing the C2P assessment plans")
+}
+
+func (m *mappingFlags) paths() (mapping.Paths, error) {
+	paths := mapping.Paths{
+		Catalogs:             splitList(m.catalogs),
+		Plans:                splitList(m.plans),
+		Rules:                splitList(m.mappingRules),
+		ComponentDefinitions: splitList(m.componentDefinitions),
+		Crosswalks:           splitList(m.crosswalks),
+	}
+	for _, pair := range splitList(m.tenantPlans) {
+		tenant, file, ok := strings.Cut(pair, "=")
+		if !ok || tenant == "" || file == "" {
+			return paths, fmt.Errorf("invalid tenant plan %q, expected tenant=file", pair)
+		}
+		if paths.PlanTenants == nil {
+			paths.PlanTenants = make(map[string]string)
+		}
+		paths.Plans = append(paths.Plans, file)
+		paths.PlanTenants[file] = tenant
+	}
+	if m.c2pPolicy != "" || m.c2pConfig != "" {
+		if m.c2pPolicy == "" || m.c2pConfig == "" {
+			return paths, fmt.Errorf("--c2p-policy and --c2p-config must be set together")
+		}
+		paths.C2P = &mapping.C2P{
+			PolicyFile: m.c2pPolicy,
+			ConfigFile: m.c2pConfig,
+			EvalDir:    m.c2pEvalDir,
+		}
+	}
+	return paths, nil
+}
+
+func (m *mappingFlags) options(reloadInterval time.Duration) ([]agent.Option, error) {
+	paths, err := m.paths()
+	if err != nil {
+		return nil, err
+	}
+	opts := []agent.Option{
+		agent.WithCatalogs(paths.Catalogs...),
+		agent.WithPlans(paths.Plans...),
+		agent.WithPlanTenants(paths.PlanTenants),
+		agent.WithMappingRules(paths.Rules...),
+		agent.WithComponentDefinitions(paths.ComponentDefinitions...),
+		agent.WithCrosswalks(paths.Crosswalks...),
+		agent.WithReloadInterval(reloadInterval),
+	}
+	if paths.C2P != nil {
+		opts = append(opts, agent.WithC2P(paths.C2P.PolicyFile, paths.C2P.ConfigFile, paths.C2P.EvalDir))
+	}
+	return opts, nil
+}
+
+// evidenceFlags configures where raw evidence is stored, shared by the agent and the
+// reproduce command.
+type evidenceFlags struct {
+	dir         string
+	s3Bucket    string
+	s3Endpoint  string
+	s3Region    string
+	s3Prefix    string
+	s3LockMode  string
+	s3Retention time.Duration
+	ociRepo     string
+	ociAttach   bool
+	ociInsecure bool
+}
+
+func (e *evidenceFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&e.dir, "evidence-dir", "", "Directory raw evidence is stored in, addressed by digest")
+	fs.StringVar(&e.s3Bucket, "evidence-s3-bucket", "", "S3 bucket raw evidence is stored in, addressed by digest. Credentials are read from the AWS environment.")
+	fs.StringVar(&e.s3Endpoint, "evidence-s3-endpoint", "", "URL of an S3-compatible service, e.g. http://localhost:9000 for MinIO. Defaults to AWS.")
+	fs.StringVar(&e.s3Region, "evidence-s3-region", "", "Region of the evidence bucket. Defaults to the AWS environment.")
+	fs.StringVar(&e.s3Prefix, "evidence-s3-prefix", "", "Prefix of evidence object keys (e.g. evidence/)")
+	fs.StringVar(&e.s3LockMode, "evidence-s3-lock-mode", "COMPLIANCE", "Object lock mode applied with --evidence-s3-retention (GOVERNANCE, COMPLIANCE)")
+	fs.DurationVar(&e.s3Retention, "evidence-s3-retention", 0, "Lock evidence objects against deletion for this duration. The bucket must have object lock enabled. Disabled when zero.")
+	fs.StringVar(&e.ociRepo, "evidence-oci-repository", "", "OCI repository raw evidence and claims are pushed to as artifacts (e.g. registry.example.com/compliance/evidence)")
+	fs.BoolVar(&e.ociAttach, "evidence-oci-attach", false, "Also attach evidence and claims to the resource image through the referrers API, when the resource names an image with a sha256 digest")
+	fs.BoolVar(&e.ociInsecure, "evidence-oci-insecure", false, "Allow evidence registries served over plain HTTP")
+}
+
+// configured reports whether an evidence store is set.
+func (e *evidenceFlags) configured() bool {
+	return e.dir != "" || e.s3Bucket != "" || e.ociRepo != ""
+}
+
+// store opens the configured evidence store, or returns nil when none is set.
+func (e *evidenceFlags) store() (evidence.Store, error) {
+	set := 0
+	for _, value := range []string{e.dir, e.s3Bucket, e.ociRepo} {
+		if value != "" {
+			set++
+		}
+	}
+	switch {
+	case set > 1:
+		return nil, fmt.Errorf("only one of --evidence-dir, --evidence-s3-bucket, or --evidence-oci-repository may be set")
+	case e.dir != "":
+		return fsevidence.Open(e.dir)
+	case e.s3Bucket != "":
+		opts := []s3evidence.Option{
+			s3evidence.WithEndpoint(e.s3Endpoint),
+			s3evidence.WithRegion(e.s3Region),
+			s3evidence.WithPrefix(e.s3Prefix),
+		}
+		if e.s3Retention > 0 {
+			opts = append(opts, s3evidence.WithObjectLock(strings.ToUpper(e.s3LockMode), e.s3Retention))
+		}
+		return s3evidence.New(e.s3Bucket, opts...)
+	case e.ociRepo != "":
+		return ocievidence.New(e.ociRepo, ocievidence.WithAttach(e.ociAttach), ocievidence.WithInsecure(e.ociInsecure))
+	default:
+		return nil, nil
+	}
+}
+
+// splitList splits a comma-separated flag value, ignoring empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// setTenant scopes an agent API request to the tenant when it is set. A proxy in front
+// of the agent may replace the header with the caller's own tenant.
+func setTenant(req *http.Request, tenant string) {
+	if tenant != "" {
+		req.Header.Set(api.TenantHeader, tenant)
+	}
+}
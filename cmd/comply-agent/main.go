@@ -3,34 +3,174 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
+
+	"github.com/in-toto/go-witness/cryptoutil"
 
 	"github.com/jpower432/shiny-journey/cmd/comply-agent/simulation"
 	"github.com/jpower432/shiny-journey/processor/agent"
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+	"github.com/jpower432/shiny-journey/processor/claims/backends/boltstore"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+	"github.com/jpower432/shiny-journey/processor/claims/retention"
 )
 
+// commands are run instead of the agent when named as the first argument.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"attest":    runAttest,
+	"coverage":  runCoverage,
+	"reproduce": runReproduce,
+	"restore":   runRestore,
+	"schema":    runSchema,
+	"snapshot":  runSnapshot,
+	"verify":    runVerify,
+}
+
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
-	if err := run(ctx); err != nil {
+	runFunc, args := run, os.Args[1:]
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			runFunc, args = command, args[1:]
+		}
+	}
+
+	if err := runFunc(ctx, args); err != nil {
 		log.Println(err)
 		os.Exit(1)
 	}
 }
 
-func run(ctx context.Context) error {
+func run(ctx context.Context, args []string) error {
 	var otelEndpoint string
 	var continuous bool
-	flag.StringVar(&otelEndpoint, "otel-endpoint", "localhost:4317", "Endpoint for the OpenTelemetry Collector")
-	flag.BoolVar(&continuous, "continuous", false, "Run continuously until canceled. Default is to run once and stop the agent.")
-	flag.Parse()
+	var strategy string
+	var methodWeights string
+	var reloadInterval time.Duration
+	var apiAddress string
+	var apiUnscoped bool
+	var storePath string
+	var retentionPolicy retention.Policy
+	var compactionInterval time.Duration
+	var transitionWebhook string
+	var restorePath string
+	var signingKey string
+	var attesterKeys string
+	var evidenceDigests string
+	var tenant string
+	var evidenceStorage evidenceFlags
+	var mappings mappingFlags
+	fs := flag.NewFlagSet("comply-agent", flag.ExitOnError)
+	fs.StringVar(&otelEndpoint, "otel-endpoint", "localhost:4317", "Endpoint for the OpenTelemetry Collector")
+	fs.BoolVar(&continuous, "continuous", false, "Run continuously until canceled. Default is to run once and stop the agent.")
+	fs.StringVar(&strategy, "aggregation-strategy", string(aggregate.AllMustPass), "Strategy for combining method results into requirement verdicts (all-must-pass, any-pass, majority, weighted)")
+	fs.StringVar(&methodWeights, "method-weights", "", "Comma-separated method weights for the weighted strategy (e.g. OPA=2,Kyverno=1)")
+	fs.DurationVar(&reloadInterval, "reload-interval", mapping.DefaultReloadInterval, "How often catalog, plan, and mapping files are checked for changes")
+	fs.StringVar(&apiAddress, "api-address", "", "Address to serve the agent API on (e.g. localhost:8090). Disabled when empty.")
+	fs.BoolVar(&apiUnscoped, "api-unscoped", false, "Allow API requests without an X-Tenant header to read every tenant. Only set for single-tenant agents or behind a proxy that sets X-Tenant for every tenant.")
+	fs.StringVar(&storePath, "store-path", "", "Path to a database file that persists claims across restarts. Claims are kept in memory when empty.")
+	fs.DurationVar(&retentionPolicy.MaxAge, "retention-max-age", 0, "Evict claims older than this duration. Disabled when zero.")
+	fs.IntVar(&retentionPolicy.MaxPerRequirement, "retention-max-per-requirement", 0, "Maximum claims kept for each catalog, requirement, and resource. Disabled when zero.")
+	fs.IntVar(&retentionPolicy.KeepLatest, "retention-keep-latest", 1, "Always keep this many of the newest claims for each requirement, resource, and method")
+	fs.DurationVar(&compactionInterval, "compaction-interval", retention.DefaultCompactionInterval, "How often retention policies are applied to the claim store")
+	fs.StringVar(&transitionWebhook, "transition-webhook", "", "URL to post requirement verdict transitions to as JSON. Disabled when empty.")
+	fs.StringVar(&restorePath, "restore-snapshot", "", "Path to a snapshot archive to restore into the empty claim store before starting")
+	fs.StringVar(&signingKey, "signing-key", "", "Path to a PEM private key used to sign claims. Claims are unsigned when empty.")
+	fs.StringVar(&tenant, "tenant", "", "Tenant of evidence that does not name one, e.g. the cluster the agent runs in")
+	fs.StringVar(&attesterKeys, "attester-keys", "", "Comma-separated PEM public keys trusted to sign manual attestations")
+	fs.StringVar(&evidenceDigests, "evidence-digests", "", "Comma-separated digest algorithms recorded for raw evidence in addition to sha256 (e.g. sha512)")
+	mappings.register(fs)
+	evidenceStorage.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := retentionPolicy.Validate(); err != nil {
+		return err
+	}
+
+	aggregationStrategy, err := aggregate.ParseStrategy(strategy)
+	if err != nil {
+		return err
+	}
+	weights, err := parseWeights(methodWeights)
+	if err != nil {
+		return err
+	}
+	aggregator, err := aggregate.New(aggregationStrategy, aggregate.WithWeights(weights))
+	if err != nil {
+		return err
+	}
+
+	mappingOpts, err := mappings.options(reloadInterval)
+	if err != nil {
+		return err
+	}
+	opts := []agent.Option{
+		agent.WithOTELCollectorEndpoint(otelEndpoint),
+		agent.WithAggregator(aggregator),
+		agent.WithAPIAddress(apiAddress),
+		agent.WithUnscopedAPIAccess(apiUnscoped),
+		agent.WithRetention(retentionPolicy, compactionInterval),
+		agent.WithTransitionWebhook(transitionWebhook),
+		agent.WithTenant(tenant),
+	}
+	opts = append(opts, mappingOpts...)
+	digestAlgorithms, err := parseDigestAlgorithms(evidenceDigests)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, agent.WithDigestAlgorithms(digestAlgorithms...))
+
+	if signingKey != "" {
+		signer, err := loadSigner(signingKey)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, agent.WithSigner(signer))
+	}
+	for _, keyPath := range splitList(attesterKeys) {
+		verifier, err := loadVerifier(keyPath)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, agent.WithAttesters(verifier))
+	}
+
+	var store claims.Store = claims.NewMemoryStore()
+	if storePath != "" {
+		boltStore, err := boltstore.Open(storePath)
+		if err != nil {
+			return err
+		}
+		defer boltStore.Close()
+		store = boltStore
+	}
+	if restorePath != "" {
+		if err := restoreSnapshot(store, restorePath); err != nil {
+			return err
+		}
+	}
+	opts = append(opts, agent.WithStore(store))
+	evidenceStore, err := evidenceStorage.store()
+	if err != nil {
+		return err
+	}
+	if evidenceStore != nil {
+		opts = append(opts, agent.WithEvidenceStore(evidenceStore))
+	}
 
 	runner := simulation.NewRunner()
-	agt := agent.New(agent.WithOTELCollectorEndpoint(otelEndpoint))
+	agt := agent.New(opts...)
 
 	if !continuous {
 		runner.RunSimulationInstance(ctx, agt)
@@ -42,3 +182,36 @@ func run(ctx context.Context) error {
 
 	return nil
 }
+
+// parseDigestAlgorithms parses a comma-separated list of digest algorithm names.
+func parseDigestAlgorithms(value string) ([]cryptoutil.DigestValue, error) {
+	var algorithms []cryptoutil.DigestValue
+	for _, algorithm := range splitList(value) {
+		hash, err := cryptoutil.HashFromString(algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported digest algorithm %q: %w", algorithm, err)
+		}
+		algorithms = append(algorithms, cryptoutil.DigestValue{Hash: hash})
+	}
+	return algorithms, nil
+}
+
+// parseWeights parses a comma-separated list of method=weight pairs.
+func parseWeights(value string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	if value == "" {
+		return weights, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		method, weight, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid method weight %q, expected method=weight", pair)
+		}
+		w, err := strconv.ParseFloat(weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for method %s: %w", method, err)
+		}
+		weights[strings.TrimSpace(method)] = w
+	}
+	return weights, nil
+}
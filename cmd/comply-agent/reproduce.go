@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/backends/boltstore"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// runReproduce re-derives a claim from its raw evidence with the given mapping files and
+// reports whether it reproduces the recorded verdict.
+func runReproduce(ctx context.Context, args []string) error {
+	var claimID, evidencePath, storePath, agentURL, tenant, output string
+	var mappings mappingFlags
+	var evidenceStorage evidenceFlags
+	fs := flag.NewFlagSet("reproduce", flag.ExitOnError)
+	fs.StringVar(&claimID, "claim", "", "ID of the claim to reproduce")
+	fs.StringVar(&evidencePath, "evidence", "", "Path to the raw evidence document the claim was produced from")
+	fs.StringVar(&storePath, "store-path", "", "Path to the claim database holding the claim")
+	fs.StringVar(&agentURL, "agent-url", "", "URL of a running agent API holding the claim (e.g. http://localhost:8090)")
+	fs.StringVar(&tenant, "tenant", "", "Tenant the claim belongs to, sent to --agent-url in the X-Tenant header")
+	fs.StringVar(&output, "output", "text", "Output format (text, json)")
+	mappings.register(fs)
+	evidenceStorage.register(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if claimID == "" {
+		return errors.New("--claim is required")
+	}
+	if evidencePath != "" && evidenceStorage.configured() {
+		return errors.New("only one of --evidence or an evidence store may be set")
+	}
+	if evidencePath == "" && !evidenceStorage.configured() && agentURL == "" {
+		return errors.New("--evidence or an evidence store is required with --store-path")
+	}
+	if (storePath == "") == (agentURL == "") {
+		return errors.New("exactly one of --store-path or --agent-url is required")
+	}
+
+	var claim claims.ConformanceClaim
+	var err error
+	if agentURL != "" {
+		claim, err = fetchClaim(ctx, agentURL, tenant, claimID)
+	} else {
+		claim, err = localClaim(storePath, claimID)
+	}
+	if err != nil {
+		return err
+	}
+	var evidenceData []byte
+	switch {
+	case evidencePath != "":
+		evidenceData, err = os.ReadFile(evidencePath)
+	case evidenceStorage.configured():
+		evidenceData, err = storedEvidence(evidenceStorage, claim.RawEvidenceRef)
+	default:
+		evidenceData, err = fetchEvidence(ctx, agentURL, tenant, claim.RawEvidenceRef)
+	}
+	if err != nil {
+		return err
+	}
+	paths, err := mappings.paths()
+	if err != nil {
+		return err
+	}
+	ruleset, err := mapping.Load(paths)
+	if err != nil {
+		return err
+	}
+
+	result, err := claims.Reproduce(claim, evidenceData, ruleset)
+	if err != nil {
+		return err
+	}
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+	case "text":
+		printReproduction(os.Stdout, claim, result)
+	default:
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	if !result.Reproducible {
+		return fmt.Errorf("claim %s was not reproduced", claimID)
+	}
+	return nil
+}
+
+func printReproduction(w io.Writer, claim claims.ConformanceClaim, result claims.Reproduction) {
+	fmt.Fprintf(w, "Claim %s: %s %s on resource '%s' (ruleset %s)\n",
+		claim.ClaimID, claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef, claim.ConfigRevision)
+	if claim.Provenance != nil {
+		fmt.Fprintf(w, "Evidence %s mapped by %s@%s, catalog version %s, agent %s\n",
+			orDash(claim.Provenance.EvidenceDigest), orDash(claim.Provenance.Mapping), orDash(claim.Provenance.MappingRevision),
+			orDash(claim.Provenance.CatalogVersion), orDash(claim.Provenance.AgentVersion))
+	}
+	for _, difference := range result.Differences {
+		fmt.Fprintf(w, "DIFFERENCE: %s\n", difference)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "WARNING: %s\n", warning)
+	}
+	if result.Reproducible {
+		fmt.Fprintln(w, "Reproduced: the evidence yields the same requirement and method results.")
+	} else {
+		fmt.Fprintln(w, "Not reproduced.")
+	}
+}
+
+func localClaim(storePath, claimID string) (claims.ConformanceClaim, error) {
+	store, err := boltstore.Open(storePath)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	defer store.Close()
+	claim, ok, err := store.Get(claimID)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if !ok {
+		return claims.ConformanceClaim{}, fmt.Errorf("claim %s: %w", claimID, claims.ErrNotFound)
+	}
+	return claim, nil
+}
+
+func fetchClaim(ctx context.Context, agentURL, tenant, claimID string) (claims.ConformanceClaim, error) {
+	endpoint, err := url.JoinPath(agentURL, "v1", "claims", claimID)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	setTenant(req, tenant)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return claims.ConformanceClaim{}, fmt.Errorf("agent returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var claim claims.ConformanceClaim
+	if err := json.NewDecoder(resp.Body).Decode(&claim); err != nil {
+		return claims.ConformanceClaim{}, fmt.Errorf("error decoding claim: %w", err)
+	}
+	return claim, nil
+}
+
+func storedEvidence(evidenceStorage evidenceFlags, ref string) ([]byte, error) {
+	store, err := evidenceStorage.store()
+	if err != nil {
+		return nil, err
+	}
+	return store.Get(ref)
+}
+
+func fetchEvidence(ctx context.Context, agentURL, tenant, ref string) ([]byte, error) {
+	endpoint, err := url.JoinPath(agentURL, "v1", "evidence", ref)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	setTenant(req, tenant)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("agent returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
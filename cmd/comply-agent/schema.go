@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// runSchema prints the JSON Schema of the claim wire format.
+func runSchema(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(claims.Schema())
+}
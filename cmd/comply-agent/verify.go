@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// runVerify verifies the signatures of claims read one per line, as exported from the
+// audit log, from --input or standard input.
+func runVerify(_ context.Context, args []string) error {
+	var publicKey, input string
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.StringVar(&publicKey, "public-key", "", "Path to the PEM public key or certificate the claims were signed with")
+	fs.StringVar(&input, "input", "-", "Path to a file of claims, one JSON document per line. Reads standard input when -.")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if publicKey == "" {
+		return errors.New("--public-key is required")
+	}
+	verifier, err := loadVerifier(publicKey)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var verified, failed int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var claim claims.ConformanceClaim
+		if err := json.Unmarshal(scanner.Bytes(), &claim); err != nil {
+			return fmt.Errorf("invalid claim on line %d: %w", line, err)
+		}
+		if err := claims.Verify(claim, verifier); err != nil {
+			fmt.Printf("FAIL %s: %v\n", claim.ClaimID, err)
+			failed++
+			continue
+		}
+		fmt.Printf("OK   %s\n", claim.ClaimID)
+		verified++
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d claims failed verification", failed, verified+failed)
+	}
+	return nil
+}
+
+func loadVerifier(path string) (cryptoutil.Verifier, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	verifier, err := cryptoutil.NewVerifierFromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public key %s: %w", path, err)
+	}
+	return verifier, nil
+}
+
+func loadSigner(path string) (cryptoutil.Signer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	signer, err := cryptoutil.NewSignerFromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signing key %s: %w", path, err)
+	}
+	return signer, nil
+}
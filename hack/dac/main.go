@@ -110,6 +110,66 @@ func main() {
 			Text:            "NON_COMPLIANT",
 			BackgroundColor: "#FF0000",
 		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "-1",
+				},
+			},
+			Text:            "NOT_APPLICABLE",
+			BackgroundColor: "#A0A0A0",
+		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "-2",
+				},
+			},
+			Text:            "NEEDS_REVIEW",
+			BackgroundColor: "#FFA500",
+		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "-3",
+				},
+			},
+			Text:            "UNKNOWN",
+			BackgroundColor: "#FFFF00",
+		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "-4",
+				},
+			},
+			Text:            "ERROR",
+			BackgroundColor: "#8B0000",
+		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "-5",
+				},
+			},
+			Text:            "STALE",
+			BackgroundColor: "#A9A9A9",
+		},
+		{
+			Condition: tablePanel.Condition{
+				Kind: tablePanel.ValueConditionKind,
+				Spec: tablePanel.ValueConditionSpec{
+					Value: "2",
+				},
+			},
+			Text:            "WAIVED",
+			BackgroundColor: "#00BFFF",
+		},
 	}
 
 	// Create the dashboard definition
@@ -149,6 +209,20 @@ func main() {
 			),
 		),
 
+		dashboard.AddVariable(
+			"tenant",
+			listVar.List(
+				labelValuesVar.PrometheusLabelValues(
+					prometheusDatasourceName,
+					labelValuesVar.LabelName("tenant"),
+					labelValuesVar.Matchers(`{__name__="compliance_assessment_status"}`),
+				),
+				listVar.DisplayName("Tenant"),
+				listVar.AllowAllValue(true),
+				listVar.AllowMultiple(true),
+			),
+		),
+
 		dashboard.AddVariable(
 			"catalogId",
 			listVar.List(
@@ -201,7 +275,7 @@ func main() {
 				),
 				panel.AddQuery(
 					query.PromQL(
-						`avg by (baseline_id) (baseline_compliance_percentage{resource=~"$evidenceResource"})`,
+						`avg by (baseline_id) (baseline_compliance_percentage{tenant=~"$tenant", resource=~"$evidenceResource"})`,
 					),
 				),
 				panel.Description("Overall percentage of compliant assessments within selected baseline(s) and resource(s)."),
@@ -222,11 +296,23 @@ func main() {
 				panel.AddLink("http://localhost:8082/"),
 				panel.AddQuery(
 					query.PromQL(
-						`requirement_binary_compliance_status{resource=~"$evidenceResource", requirement_id=~"$requirementId", baseline_id=~"$catalogId"}`,
+						`requirement_binary_compliance_status{tenant=~"$tenant", resource=~"$evidenceResource", requirement_id=~"$requirementId", baseline_id=~"$catalogId"}`,
 					),
 				),
 				panel.Description("Detailed status of each requirement, filtered by selected dimensions."),
 			),
+			panelgroup.AddPanel("Unresolved and Waived Assessments",
+				tablePanel.Table(
+					tablePanel.WithColumnSettings(columnSettings),
+					tablePanel.WithCellSettings(cellSettings),
+				),
+				panel.AddQuery(
+					query.PromQL(
+						`compliance_assessment_status{assessment_status_raw=~"NEEDS_REVIEW|UNKNOWN|ERROR|STALE|WAIVED", tenant=~"$tenant", resource=~"$evidenceResource", requirement_id=~"$requirementId", baseline_id=~"$catalogId"}`,
+					),
+				),
+				panel.Description("Assessments that need review, could not be interpreted, failed to evaluate, or were waived."),
+			),
 		),
 
 		dashboard.AddPanelGroup("Evidence Stats",
@@ -235,7 +321,7 @@ func main() {
 				statPanel.Chart(),
 				panel.AddQuery(
 					query.PromQL(
-						`sum(non_compliant_requirements_count{resource=~"$evidenceResource"})`,
+						`sum(non_compliant_requirements_count{tenant=~"$tenant", resource=~"$evidenceResource"})`,
 					),
 				),
 			),
@@ -243,7 +329,7 @@ func main() {
 				timeSeriesPanel.Chart(),
 				panel.AddQuery(
 					query.PromQL(
-						`rate(evidence_processed_total{evidence_resource=~"$evidenceResource", evidence_source=~"$evidenceSource", job="agent"}[5m])`,
+						`rate(evidence_processed_total{tenant=~"$tenant", evidence_resource=~"$evidenceResource", evidence_source=~"$evidenceSource", job="agent"}[5m])`,
 					),
 				),
 			),
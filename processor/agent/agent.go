@@ -2,28 +2,28 @@ package agent
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
-	"github.com/revanite-io/sci/layer4"
+	"github.com/in-toto/go-witness/dsse"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/jpower432/shiny-journey/processor/api"
 	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
 	"github.com/jpower432/shiny-journey/processor/claims/backends/auditlog"
+	"github.com/jpower432/shiny-journey/processor/claims/drift"
 	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+	"github.com/jpower432/shiny-journey/processor/claims/retention"
 )
 
 var (
 	otelShutdown func(ctx context.Context) error
-	plan         = layer4.Layer4{
-		CatalogID: "EXMP-10001",
-	}
 )
 
 // Agent handles processing raw evidence, generating claims, and exporting data.
@@ -32,7 +32,12 @@ type Agent struct {
 	shutdownChan    chan struct{}
 	waitGroup       *sync.WaitGroup
 	options         agentOptions
-	store           *claims.Store
+	store           claims.Store
+	rules           *mapping.Watcher
+	stopBackground  context.CancelFunc
+	apiServer       *api.Server
+	drift           *drift.Detector
+	webhook         *drift.Webhook
 }
 
 func New(opts ...Option) *Agent {
@@ -42,13 +47,18 @@ func New(opts ...Option) *Agent {
 		opt(&options)
 	}
 
-	return &Agent{
+	agent := &Agent{
 		rawEvidenceChan: make(chan evidence.RawEvidence, 100), // Buffered channel for incoming evidence
 		shutdownChan:    make(chan struct{}),
 		waitGroup:       &sync.WaitGroup{},
 		options:         options,
-		store:           claims.NewStore(),
+		store:           options.store,
+		drift:           drift.NewDetector(options.aggregator),
+	}
+	if options.transitionWebhook != "" {
+		agent.webhook = drift.NewWebhook(options.transitionWebhook)
 	}
+	return agent
 }
 
 // Start begins listening for raw evidence and processing it.
@@ -67,7 +77,55 @@ func (a *Agent) Start(ctx context.Context) {
 		if err != nil {
 			log.Fatalf("error with instrumentation: %v", err)
 		}
-		metricsConfigure(a.store)
+		metricsConfigure(a.store, a, a.options.aggregator)
+	}
+
+	var err error
+	a.rules, err = mapping.NewWatcher(a.options.mappingPaths, a.options.reloadInterval)
+	if err != nil {
+		log.Fatalf("failed to load mapping ruleset: %v", err)
+	}
+	log.Printf("Using mapping ruleset %s", a.rules.Current().Revision)
+	for _, binding := range a.rules.Current().Bindings {
+		if !claims.SupportsSource(binding.Source) {
+			log.Fatalf("plugin %s enforces catalog %s but evidence source %s is not supported", binding.PluginID, binding.CatalogID, binding.Source)
+		}
+		log.Printf("Plugin %s enforces catalog %s for service %s, mapping %s evidence", binding.PluginID, binding.CatalogID, binding.Service, binding.Source)
+	}
+
+	var backgroundCtx context.Context
+	backgroundCtx, a.stopBackground = context.WithCancel(ctx)
+	a.waitGroup.Add(1)
+	go func() {
+		defer a.waitGroup.Done()
+		a.rules.Run(backgroundCtx)
+	}()
+
+	if a.webhook != nil {
+		a.waitGroup.Add(1)
+		go func() {
+			defer a.waitGroup.Done()
+			a.webhook.Run(backgroundCtx)
+		}()
+	}
+
+	if a.options.retention.Enabled() {
+		log.Printf("Compacting claim store every %s", a.options.compactionInterval)
+		compactor := retention.NewCompactor(a.store, a.options.retention, a.options.compactionInterval, a.handleEvictions)
+		a.waitGroup.Add(1)
+		go func() {
+			defer a.waitGroup.Done()
+			compactor.Run(backgroundCtx)
+		}()
+	}
+
+	if a.options.apiAddress != "" {
+		a.apiServer = api.NewServer(a.options.apiAddress, a, api.WithUnscopedAccess(a.options.apiUnscoped))
+		go func() {
+			if err := a.apiServer.ListenAndServe(); err != nil {
+				log.Fatalf("failed to serve agent API: %v", err)
+			}
+		}()
 	}
 
 	// Add the main processing loop to the waitGroup
@@ -98,6 +156,19 @@ func (a *Agent) Stop(ctx context.Context) {
 
 	// Signal the main processing loop to shut down
 	close(a.shutdownChan)
+	if a.stopBackground != nil {
+		a.stopBackground()
+	}
+
+	if a.apiServer != nil {
+		a.waitGroup.Add(1)
+		go func() {
+			defer a.waitGroup.Done()
+			if err := a.apiServer.Shutdown(ctx); err != nil {
+				log.Printf("Error during API server shutdown: %v", err)
+			}
+		}()
+	}
 
 	if otelShutdown != nil {
 		a.waitGroup.Add(1)
@@ -137,27 +208,305 @@ func (a *Agent) IngestRawEvidence(ev evidence.RawEvidence) {
 }
 
 // processEvidence maps raw data to claims and pushes to storage.
+// The ruleset is read once so a reload during processing cannot mix revisions.
 func (a *Agent) processEvidence(ctx context.Context, rawEv evidence.RawEvidence) error {
-	rawEvJSON, err := json.MarshalIndent(rawEv, "", "  ")
+	ruleset := a.rules.Current()
+	if rawEv.Tenant == "" {
+		rawEv.Tenant = a.options.tenant
+	}
+
+	rawEvJSON, err := evidence.Marshal(rawEv)
 	if err != nil {
 		return fmt.Errorf("error marshaling raw evidence %s: %v", rawEv.ID, err)
 	}
-	rawEvidenceHash := sha256.Sum256(rawEvJSON)
-	rawEvidenceRef := hex.EncodeToString(rawEvidenceHash[:]) // Use hash as reference
+	rawEvidenceRef := evidence.Digest(rawEvJSON) // Use hash as reference
+
+	err = a.exportEvidence(rawEvidenceRef, rawEvJSON)
+	if err != nil {
+		return err
+	}
+	return a.logEvidence(ctx, rawEv, rawEvJSON, rawEvidenceRef, ruleset)
+}
+
+// exportEvidence stores raw evidence in the configured evidence store, or logs it
+// when none is configured.
+func (a *Agent) exportEvidence(ref string, data []byte) error {
+	if a.options.evidenceStore == nil {
+		return evidence.Export(ref, data)
+	}
+	if err := a.options.evidenceStore.Put(ref, data); err != nil {
+		return fmt.Errorf("failed to store raw evidence %s: %w", ref, err)
+	}
+	return nil
+}
 
-	err = evidence.Export(rawEvidenceRef, rawEvJSON)
+func (a *Agent) logEvidence(ctx context.Context, rawEv evidence.RawEvidence, rawEvJSON []byte, rawEnvRef string, ruleset *mapping.Ruleset) error {
+	claim, err := claims.NewFromEvidence(rawEv, rawEnvRef, ruleset)
 	if err != nil {
 		return err
 	}
-	return a.logEvidence(ctx, rawEv, rawEvidenceRef)
+	if err := claim.Provenance.RecordEvidence(rawEvJSON, a.options.digestAlgorithms...); err != nil {
+		return err
+	}
+	if err := a.sign(claim); err != nil {
+		return err
+	}
+	if err := auditlog.Emit(ctx, claim); err != nil {
+		return err
+	}
+	log.Printf("Logged evidence with claim id %s (ruleset %s)\n", claim.ClaimID, claim.ConfigRevision)
+	if err := a.storeClaim(ctx, *claim, ruleset); err != nil {
+		return err
+	}
+	return a.deriveClaims(ctx, *claim, ruleset)
+}
+
+// SubmitAttestation verifies a signed manual attestation and stores the claim made from it.
+// The attestation must be made for the tenant, or for the agent's tenant when tenant is
+// empty. Attestations that do not name a tenant are made for it.
+func (a *Agent) SubmitAttestation(ctx context.Context, envelope dsse.Envelope, tenant string) (claims.ConformanceClaim, error) {
+	attestation, err := claims.VerifyAttestation(envelope, a.options.attesters...)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if tenant == "" {
+		tenant = evidence.TenantName(a.options.tenant)
+	}
+	if attestation.Tenant == "" {
+		attestation.Tenant = tenant
+	}
+	if evidence.TenantName(attestation.Tenant) != tenant {
+		return claims.ConformanceClaim{}, fmt.Errorf("%w: attestation for tenant %s cannot be submitted by tenant %s",
+			claims.ErrUntrustedAttestation, evidence.TenantName(attestation.Tenant), tenant)
+	}
+	envelopeJSON, err := json.Marshal(envelope)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if envelopeJSON, err = evidence.Canonicalize(envelopeJSON); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	envelopeRef := evidence.Digest(envelopeJSON)
+	ruleset := a.Ruleset()
+	claim, err := claims.NewFromAttestation(attestation, envelopeRef, ruleset)
+	if err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if err := a.exportEvidence(envelopeRef, envelopeJSON); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if err := claim.Provenance.RecordEvidence(envelopeJSON, a.options.digestAlgorithms...); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if err := a.sign(claim); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if err := auditlog.Emit(ctx, claim); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	log.Printf("Logged manual attestation by %s with claim id %s\n", attestation.Attester, claim.ClaimID)
+	if err := a.storeClaim(ctx, *claim, ruleset); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	if err := a.deriveClaims(ctx, *claim, ruleset); err != nil {
+		return claims.ConformanceClaim{}, err
+	}
+	return *claim, nil
+}
+
+// deriveClaims reports the native claim against every framework mapped through a crosswalk.
+func (a *Agent) deriveClaims(ctx context.Context, native claims.ConformanceClaim, ruleset *mapping.Ruleset) error {
+	target := mapping.Target{
+		CatalogID:     native.CatalogID,
+		ControlID:     native.ControlID,
+		RequirementID: native.Assessment.RequirementID,
+	}
+	for _, derivation := range ruleset.Derive(target) {
+		derived := claims.Derive(native, derivation)
+		if err := a.sign(derived); err != nil {
+			return err
+		}
+		if err := auditlog.Emit(ctx, derived); err != nil {
+			return err
+		}
+		log.Printf("Logged derived claim id %s for %s %s from claim %s\n",
+			derived.ClaimID, derived.CatalogID, derived.Assessment.RequirementID, native.ClaimID)
+		if err := a.storeClaim(ctx, *derived, ruleset); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (a *Agent) logEvidence(ctx context.Context, rawEv evidence.RawEvidence, rawEnvRef string) error {
-	claim, err := auditlog.LogClaim(ctx, rawEv, rawEnvRef, plan)
+// sign signs the claim with the configured signer. Claims are left unsigned without one.
+func (a *Agent) sign(claim *claims.ConformanceClaim) error {
+	if a.options.signer == nil {
+		return nil
+	}
+	return claims.Sign(claim, a.options.signer)
+}
+
+// storeClaim adds the claim to the store and reports any verdict transition or method
+// conflict it causes for its requirement and resource. The ruleset is the one the claim
+// was made with, so waivers are applied at the claim's revision.
+func (a *Agent) storeClaim(ctx context.Context, claim claims.ConformanceClaim, ruleset *mapping.Ruleset) error {
+	before, err := a.currentFor(claim, ruleset)
+	if err != nil {
+		return err
+	}
+	if err := a.store.Add(claim); err != nil {
+		return fmt.Errorf("failed to store claim %s: %w", claim.ClaimID, err)
+	}
+	a.exportClaim(claim)
+	after, err := a.currentFor(claim, ruleset)
 	if err != nil {
 		return err
 	}
-	log.Printf("Logged evidence with claim id %s\n", claim.ClaimID)
-	a.store.Add(*claim)
+	if transition, ok := a.drift.Detect(claim, before, after); ok {
+		a.reportTransition(ctx, transition)
+	}
+	a.reportConflicts(after)
 	return nil
 }
+
+// handleEvictions records evicted claims and forgets open regressions for requirements
+// and resources left without claims.
+func (a *Agent) handleEvictions(ctx context.Context, evictions []retention.Eviction) {
+	recordEvictions(ctx, evictions)
+	current, err := a.store.Current()
+	if err != nil {
+		log.Printf("Error pruning open regressions: %v", err)
+		return
+	}
+	if pruned := a.drift.Prune(current); pruned > 0 {
+		log.Printf("Forgot %d open regressions without current claims", pruned)
+	}
+}
+
+// claimExporter is implemented by evidence stores that keep claims next to the evidence
+// they were made from.
+type claimExporter interface {
+	PutClaim(claim claims.ConformanceClaim) error
+}
+
+// exportClaim sends the claim to the evidence store when it keeps claims. The claim is
+// already stored, so failures are only logged.
+func (a *Agent) exportClaim(claim claims.ConformanceClaim) {
+	exporter, ok := a.options.evidenceStore.(claimExporter)
+	if !ok {
+		return
+	}
+	if err := exporter.PutClaim(claim); err != nil {
+		log.Printf("Error exporting claim %s to the evidence store: %v", claim.ClaimID, err)
+	}
+}
+
+// currentFor returns the current claims for the claim's tenant, requirement, and resource,
+// with the waivers in the ruleset applied.
+func (a *Agent) currentFor(claim claims.ConformanceClaim, ruleset *mapping.Ruleset) ([]claims.ConformanceClaim, error) {
+	result, err := a.store.Query(claims.Query{
+		Tenant:        claim.TenantID(),
+		CatalogID:     claim.CatalogID,
+		RequirementID: claim.Assessment.RequirementID,
+		ResourceRef:   claim.ResourceRef,
+		CurrentOnly:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current claims for requirement %s: %w", claim.Assessment.RequirementID, err)
+	}
+	// Waived failures are accepted risks, not regressions.
+	return claims.ApplyWaivers(result.Claims, ruleset, time.Now()), nil
+}
+
+// reportTransition logs, counts, and queues a verdict transition for the webhook.
+func (a *Agent) reportTransition(ctx context.Context, transition drift.Transition) {
+	log.Printf("Requirement %s on resource %s of tenant %s changed from %s to %s (%s, claim %s)",
+		transition.RequirementID, transition.ResourceRef, transition.Tenant, transition.From, transition.To, transition.Kind, transition.After.ClaimID)
+	recordTransition(ctx, transition)
+	if err := auditlog.EmitTransition(ctx, transition); err != nil {
+		log.Printf("Error logging transition for claim %s: %v", transition.After.ClaimID, err)
+	}
+	if a.webhook != nil && !a.webhook.Notify(transition) {
+		log.Printf("Warning: Transition webhook queue full, dropping transition for claim %s", transition.After.ClaimID)
+	}
+}
+
+// Ruleset returns the active mapping ruleset.
+func (a *Agent) Ruleset() *mapping.Ruleset {
+	if a.rules == nil {
+		return mapping.Default()
+	}
+	return a.rules.Current()
+}
+
+// Claims returns all claims in the store.
+func (a *Agent) Claims() ([]claims.ConformanceClaim, error) {
+	return a.store.GetClaims()
+}
+
+// Claim returns the stored claim with the given ID.
+func (a *Agent) Claim(claimID string) (claims.ConformanceClaim, bool, error) {
+	return a.store.Get(claimID)
+}
+
+// Evidence returns the raw evidence stored under the reference.
+func (a *Agent) Evidence(ref string) ([]byte, error) {
+	if a.options.evidenceStore == nil {
+		return nil, fmt.Errorf("evidence %s: %w: no evidence store is configured", ref, evidence.ErrNotFound)
+	}
+	return a.options.evidenceStore.Get(ref)
+}
+
+// QueryClaims returns a page of the stored claims matching the query.
+func (a *Agent) QueryClaims(q claims.Query) (claims.QueryResult, error) {
+	return a.store.Query(q)
+}
+
+// Watch streams claim store events after the given revision until the context is canceled.
+func (a *Agent) Watch(ctx context.Context, afterRevision uint64) (<-chan claims.Event, error) {
+	return a.store.Watch(ctx, afterRevision)
+}
+
+// SetLegalHold places or releases a legal hold, which exempts a claim from retention.
+func (a *Agent) SetLegalHold(claimID string, hold bool) error {
+	return a.store.SetLegalHold(claimID, hold)
+}
+
+// Current returns the current posture, with failures covered by an active waiver
+// reported as WAIVED and methods whose evidence is older than the expected cadence
+// reported as STALE.
+func (a *Agent) Current() ([]claims.ConformanceClaim, error) {
+	current, err := a.store.Current()
+	if err != nil {
+		return nil, err
+	}
+	return a.postureView(current), nil
+}
+
+// postureView applies waivers and freshness to current claims. Stale evidence is
+// reported as STALE even when waived, since the waived failure may no longer hold.
+func (a *Agent) postureView(current []claims.ConformanceClaim) []claims.ConformanceClaim {
+	ruleset, now := a.Ruleset(), time.Now()
+	return claims.MarkStale(claims.ApplyWaivers(current, ruleset, now), ruleset, now)
+}
+
+// Verdicts returns the requirement-level verdicts for the current posture.
+func (a *Agent) Verdicts() ([]aggregate.Verdict, error) {
+	allClaims, err := a.Current()
+	if err != nil {
+		return nil, err
+	}
+	return a.options.aggregator.Aggregate(allClaims), nil
+}
+
+// reportConflicts logs when the current claims for a requirement and resource, as
+// returned by currentFor, leave it with conflicting method results. Only that posture
+// key is aggregated.
+func (a *Agent) reportConflicts(current []claims.ConformanceClaim) {
+	for _, verdict := range a.options.aggregator.Aggregate(current) {
+		if verdict.Conflict {
+			log.Printf("Conflicting method results for requirement %s on resource %s: %v (verdict %s using %s)",
+				verdict.RequirementID, verdict.ResourceRef, verdict.Methods, verdict.Status, verdict.Strategy)
+		}
+	}
+}
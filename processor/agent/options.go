@@ -1,7 +1,15 @@
 package agent
 
 import (
+	"time"
+
 	"github.com/in-toto/go-witness/cryptoutil"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+	"github.com/jpower432/shiny-journey/processor/claims/retention"
 )
 
 type agentOptions struct {
@@ -9,11 +17,28 @@ type agentOptions struct {
 	evidenceEndpoint    string
 	attestationEndpoint string
 	signer              cryptoutil.Signer
+	attesters           []cryptoutil.Verifier
+	aggregator          *aggregate.Aggregator
+	mappingPaths        mapping.Paths
+	reloadInterval      time.Duration
+	apiAddress          string
+	apiUnscoped         bool
+	store               claims.Store
+	evidenceStore       evidence.Store
+	digestAlgorithms    []cryptoutil.DigestValue
+	retention           retention.Policy
+	compactionInterval  time.Duration
+	transitionWebhook   string
+	tenant              string
 }
 
 func (o *agentOptions) defaults() {
 	o.attestationEndpoint = "http://localhost:8082"
 	o.otelEndpoint = "localhost:4317"
+	o.aggregator, _ = aggregate.New(aggregate.AllMustPass)
+	o.reloadInterval = mapping.DefaultReloadInterval
+	o.store = claims.NewMemoryStore()
+	o.compactionInterval = retention.DefaultCompactionInterval
 }
 
 type Option func(ao *agentOptions)
@@ -37,3 +62,147 @@ func WithSigner(signer cryptoutil.Signer) Option {
 		ao.signer = signer
 	}
 }
+
+// WithAttesters sets the keys trusted to sign manual attestations.
+func WithAttesters(verifiers ...cryptoutil.Verifier) Option {
+	return func(ao *agentOptions) {
+		ao.attesters = append(ao.attesters, verifiers...)
+	}
+}
+
+// WithAggregator sets how method results are combined into requirement verdicts.
+func WithAggregator(aggregator *aggregate.Aggregator) Option {
+	return func(ao *agentOptions) {
+		ao.aggregator = aggregator
+	}
+}
+
+// WithCatalogs sets the catalog files used to validate mappings.
+func WithCatalogs(paths ...string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.Catalogs = append(ao.mappingPaths.Catalogs, paths...)
+	}
+}
+
+// WithPlans sets the assessment plan files used to map evidence to requirements.
+func WithPlans(paths ...string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.Plans = append(ao.mappingPaths.Plans, paths...)
+	}
+}
+
+// WithPlanTenants restricts plan files to evidence from a single tenant, keyed by plan file.
+// The files must also be set with WithPlans.
+func WithPlanTenants(tenants map[string]string) Option {
+	return func(ao *agentOptions) {
+		if ao.mappingPaths.PlanTenants == nil {
+			ao.mappingPaths.PlanTenants = make(map[string]string, len(tenants))
+		}
+		for file, tenant := range tenants {
+			ao.mappingPaths.PlanTenants[file] = tenant
+		}
+	}
+}
+
+// WithMappingRules sets the mapping rule files used to map evidence to requirements.
+func WithMappingRules(paths ...string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.Rules = append(ao.mappingPaths.Rules, paths...)
+	}
+}
+
+// WithComponentDefinitions sets OSCAL component definition files whose implemented
+// requirements map policy rules to controls.
+func WithComponentDefinitions(paths ...string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.ComponentDefinitions = append(ao.mappingPaths.ComponentDefinitions, paths...)
+	}
+}
+
+// WithCrosswalks sets mapping documents between catalogs used to derive claims in other frameworks.
+func WithCrosswalks(paths ...string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.Crosswalks = append(ao.mappingPaths.Crosswalks, paths...)
+	}
+}
+
+// WithReloadInterval sets how often catalog, plan, and mapping files are checked for changes.
+func WithReloadInterval(interval time.Duration) Option {
+	return func(ao *agentOptions) {
+		ao.reloadInterval = interval
+	}
+}
+
+// WithC2P derives catalogs, plans, and evidence sources from the compliance-to-policy
+// files used to generate policies.
+func WithC2P(policyFile, configFile, evalDir string) Option {
+	return func(ao *agentOptions) {
+		ao.mappingPaths.C2P = &mapping.C2P{
+			PolicyFile: policyFile,
+			ConfigFile: configFile,
+			EvalDir:    evalDir,
+		}
+	}
+}
+
+// WithAPIAddress serves the agent API on the given address. The API is disabled when empty.
+func WithAPIAddress(address string) Option {
+	return func(ao *agentOptions) {
+		ao.apiAddress = address
+	}
+}
+
+// WithUnscopedAPIAccess allows API requests without the X-Tenant header to read every
+// tenant. Such requests are rejected by default.
+func WithUnscopedAPIAccess(allow bool) Option {
+	return func(ao *agentOptions) {
+		ao.apiUnscoped = allow
+	}
+}
+
+// WithStore sets where claims are stored. Claims are kept in memory by default.
+// The caller remains responsible for closing the store.
+func WithStore(store claims.Store) Option {
+	return func(ao *agentOptions) {
+		ao.store = store
+	}
+}
+
+// WithEvidenceStore sets where raw evidence is stored under the reference recorded on
+// claims. Evidence is only printed by default.
+func WithEvidenceStore(store evidence.Store) Option {
+	return func(ao *agentOptions) {
+		ao.evidenceStore = store
+	}
+}
+
+// WithDigestAlgorithms records digests of raw evidence with the algorithms in claim
+// provenance, in addition to the sha256 digest of the evidence reference.
+func WithDigestAlgorithms(algorithms ...cryptoutil.DigestValue) Option {
+	return func(ao *agentOptions) {
+		ao.digestAlgorithms = append(ao.digestAlgorithms, algorithms...)
+	}
+}
+
+// WithRetention evicts claims from the store according to the policy, checking on every interval.
+func WithRetention(policy retention.Policy, interval time.Duration) Option {
+	return func(ao *agentOptions) {
+		ao.retention = policy
+		ao.compactionInterval = interval
+	}
+}
+
+// WithTransitionWebhook posts every requirement verdict transition to the URL.
+func WithTransitionWebhook(url string) Option {
+	return func(ao *agentOptions) {
+		ao.transitionWebhook = url
+	}
+}
+
+// WithTenant sets the tenant of evidence that does not name one, such as the cluster the
+// agent runs in. Evidence without a tenant belongs to the default tenant otherwise.
+func WithTenant(tenant string) Option {
+	return func(ao *agentOptions) {
+		ao.tenant = tenant
+	}
+}
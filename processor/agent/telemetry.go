@@ -20,8 +20,11 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+	"github.com/jpower432/shiny-journey/processor/claims/drift"
 	"github.com/jpower432/shiny-journey/processor/claims/evidence"
 	"github.com/jpower432/shiny-journey/processor/claims/metrics"
+	"github.com/jpower432/shiny-journey/processor/claims/retention"
 )
 
 const name = "go.opentelemetry.io/otel/example/agent"
@@ -29,7 +32,11 @@ const name = "go.opentelemetry.io/otel/example/agent"
 var (
 	meter           = otel.Meter(name)
 	evidenceCounter metric.Int64Counter
-	serviceName     = semconv.ServiceNameKey.String("agent")
+	evictionCounter metric.Int64Counter
+	// transitionCounter and remediationHistogram track verdict drift.
+	transitionCounter    metric.Int64Counter
+	remediationHistogram metric.Float64Histogram
+	serviceName          = semconv.ServiceNameKey.String("agent")
 )
 
 // otelSDKSetup completes setup of the Otel SDK with providers.
@@ -78,7 +85,7 @@ func otelSDKSetup(ctx context.Context, conn *grpc.ClientConn) (func(context.Cont
 	return shutDown, nil
 }
 
-func metricsConfigure(store *claims.Store) {
+func metricsConfigure(store claims.Store, posture metrics.Posture, aggregator *aggregate.Aggregator) {
 	var err error
 	evidenceCounter, err = meter.Int64Counter("evidence_processed",
 		metric.WithDescription("The number of evidence artifacts processed."),
@@ -87,7 +94,38 @@ func metricsConfigure(store *claims.Store) {
 		log.Fatalf("%v", err)
 	}
 
-	_, err = metrics.NewComplianceObserver(meter, store)
+	evictionCounter, err = meter.Int64Counter("claim_store_evictions",
+		metric.WithDescription("The number of claims evicted from the claim store by retention policies."),
+		metric.WithUnit("1"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	transitionCounter, err = meter.Int64Counter("compliance_transitions",
+		metric.WithDescription("The number of requirement verdict transitions, by kind and status."),
+		metric.WithUnit("1"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	remediationHistogram, err = meter.Float64Histogram("compliance_time_to_remediate",
+		metric.WithDescription("Time from a requirement regressing to NOT_COMPLIANT until it was remediated."),
+		metric.WithUnit("s"))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	_, err = metrics.NewStoreObserver(meter, store)
+	if err != nil {
+		log.Fatalf("failed to register callback: %v", err)
+	}
+
+	_, err = metrics.NewComplianceObserver(meter, posture)
+	if err != nil {
+		log.Fatalf("failed to register callback: %v", err)
+	}
+
+	_, err = metrics.NewVerdictObserver(meter, posture, aggregator)
 	if err != nil {
 		log.Fatalf("failed to register callback: %v", err)
 	}
@@ -100,6 +138,34 @@ func increment(ctx context.Context, rawEnv evidence.RawEvidence) {
 	attrs := []attribute.KeyValue{
 		attribute.String("evidence_source", rawEnv.Source),
 		attribute.String("evidence_resource", rawEnv.Resource.Name),
+		attribute.String("tenant", evidence.TenantName(rawEnv.Tenant)),
 	}
 	evidenceCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
+
+func recordEvictions(ctx context.Context, evictions []retention.Eviction) {
+	if evictionCounter == nil {
+		return
+	}
+	for _, eviction := range evictions {
+		evictionCounter.Add(ctx, 1, metric.WithAttributes(attribute.String("reason", eviction.Reason)))
+	}
+}
+
+func recordTransition(ctx context.Context, transition drift.Transition) {
+	if transitionCounter == nil {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("kind", string(transition.Kind)),
+		attribute.String("from", transition.From),
+		attribute.String("to", transition.To),
+		attribute.String("baseline_id", transition.CatalogID),
+		attribute.String("requirement_id", transition.RequirementID),
+		attribute.String("tenant", transition.Tenant),
+	}
+	transitionCounter.Add(ctx, 1, metric.WithAttributes(attrs...))
+	if transition.Kind == drift.Remediation && transition.TimeToRemediateSeconds > 0 {
+		remediationHistogram.Record(ctx, transition.TimeToRemediateSeconds, metric.WithAttributes(attrs[3:]...))
+	}
+}
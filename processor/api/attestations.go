@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/in-toto/go-witness/dsse"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// maxAttestationSize limits the size of a submitted attestation envelope.
+const maxAttestationSize = 1 << 20
+
+// handleAttestation stores a claim from a manual attestation submitted as a DSSE
+// envelope signed by a trusted attester. The attestation must be made for the tenant of
+// the request, or for the agent's tenant when an unscoped request does not name one.
+func (s *Server) handleAttestation(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	var envelope dsse.Envelope
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAttestationSize)).Decode(&envelope); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid attestation envelope: %w", err))
+		return
+	}
+	claim, err := s.backend.SubmitAttestation(r.Context(), envelope, tenant)
+	switch {
+	case errors.Is(err, claims.ErrUntrustedAttestation):
+		writeError(w, http.StatusForbidden, err)
+	case errors.Is(err, claims.ErrInvalidAttestation):
+		writeError(w, http.StatusBadRequest, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusCreated, claim)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// handleClaims returns a page of claims filtered by the tenant, catalog, control,
+// requirement, resource, status, source, current, since, and until query parameters.
+func (s *Server) handleClaims(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	q, err := parseQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	q.Tenant = tenant
+	if err := q.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	result, err := s.backend.QueryClaims(q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleClaim returns the claim named in the path. Claims of other tenants are not found.
+func (s *Server) handleClaim(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	claimID := r.PathValue("id")
+	claim, ok, err := s.backend.Claim(claimID)
+	switch {
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	case !ok || !inScope(claim, tenant):
+		writeError(w, http.StatusNotFound, fmt.Errorf("claim %s: %w", claimID, claims.ErrNotFound))
+	default:
+		writeJSON(w, http.StatusOK, claim)
+	}
+}
+
+// handleLegalHold places or releases a legal hold on the claim named in the path.
+func (s *Server) handleLegalHold(hold bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tenant, ok := s.tenantScope(w, r)
+		if !ok {
+			return
+		}
+		claimID := r.PathValue("id")
+		claim, ok, err := s.backend.Claim(claimID)
+		if err == nil && (!ok || !inScope(claim, tenant)) {
+			err = fmt.Errorf("claim %s: %w", claimID, claims.ErrNotFound)
+		}
+		if err == nil {
+			err = s.backend.SetLegalHold(claimID, hold)
+		}
+		switch {
+		case errors.Is(err, claims.ErrNotFound):
+			writeError(w, http.StatusNotFound, err)
+		case err != nil:
+			writeError(w, http.StatusInternalServerError, err)
+		default:
+			writeJSON(w, http.StatusOK, map[string]any{"claimId": claimID, "legalHold": hold})
+		}
+	}
+}
+
+func parseQuery(values url.Values) (claims.Query, error) {
+	q := claims.Query{
+		CatalogID:     values.Get("catalog"),
+		ControlID:     values.Get("control"),
+		RequirementID: values.Get("requirement"),
+		ResourceRef:   values.Get("resource"),
+		Status:        values.Get("status"),
+		Source:        values.Get("source"),
+		Order:         claims.SortOrder(values.Get("order")),
+		Cursor:        values.Get("cursor"),
+		Limit:         defaultQueryLimit,
+	}
+	var err error
+	if current := values.Get("current"); current != "" {
+		if q.CurrentOnly, err = strconv.ParseBool(current); err != nil {
+			return q, fmt.Errorf("invalid current value %q", current)
+		}
+	}
+	if q.Start, err = parseTime(values, "since"); err != nil {
+		return q, err
+	}
+	if q.End, err = parseTime(values, "until"); err != nil {
+		return q, err
+	}
+	if limit := values.Get("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit < 1 || q.Limit > maxQueryLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxQueryLimit)
+		}
+	}
+	return q, nil
+}
+
+func parseTime(values url.Values, name string) (time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s time, expected RFC 3339: %w", name, err)
+	}
+	return t, nil
+}
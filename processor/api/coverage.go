@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/jpower432/shiny-journey/processor/claims/coverage"
+)
+
+// handleCoverage returns the evidence coverage report for the catalog given by the
+// catalog query parameter, or for every loaded catalog when it is omitted. Evidence is
+// limited to the tenant of the request.
+func (s *Server) handleCoverage(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	ruleset := s.backend.Ruleset()
+	allClaims, err := s.backend.Claims()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	allClaims = scopeClaims(allClaims, tenant)
+
+	catalogID := r.URL.Query().Get("catalog")
+	if catalogID == "" {
+		reports, err := coverage.NewReports(ruleset, allClaims)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, reports)
+		return
+	}
+
+	report, err := coverage.NewReport(ruleset, catalogID, allClaims)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, []coverage.Report{report})
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// handleEvidence returns the raw evidence document stored under the reference in the
+// path, as recorded in a claim's rawEvidenceRef. Scoped requests may only read evidence
+// referenced by a claim of their tenant.
+func (s *Server) handleEvidence(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	ref := r.PathValue("ref")
+	notFound := fmt.Errorf("evidence %s: %w", ref, evidence.ErrNotFound)
+	if tenant != "" {
+		result, err := s.backend.QueryClaims(claims.Query{Tenant: tenant})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !referenced(result.Claims, ref) {
+			writeError(w, http.StatusNotFound, notFound)
+			return
+		}
+	}
+	data, err := s.backend.Evidence(ref)
+	switch {
+	case errors.Is(err, evidence.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, evidence.ErrInvalidRef):
+		writeError(w, http.StatusBadRequest, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"`+ref+`"`)
+		_, _ = w.Write(data)
+	}
+}
+
+func referenced(all []claims.ConformanceClaim, ref string) bool {
+	for _, claim := range all {
+		if evidence.SameRef(claim.RawEvidenceRef, ref) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+// Package api exposes agent state over HTTP as JSON.
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/in-toto/go-witness/dsse"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// Backend provides the agent state served by the API.
+type Backend interface {
+	// Ruleset returns the active mapping ruleset.
+	Ruleset() *mapping.Ruleset
+	// Claims returns all claims in the store.
+	Claims() ([]claims.ConformanceClaim, error)
+	// Claim returns the claim with the given ID.
+	Claim(claimID string) (claims.ConformanceClaim, bool, error)
+	// Evidence returns the raw evidence stored under the reference.
+	Evidence(ref string) ([]byte, error)
+	// QueryClaims returns a page of the claims matching the query.
+	QueryClaims(q claims.Query) (claims.QueryResult, error)
+	// SetLegalHold places or releases a legal hold on a claim.
+	SetLegalHold(claimID string, hold bool) error
+	// SubmitAttestation stores a claim from a signed manual attestation made for the
+	// tenant. An empty tenant is the agent's own tenant.
+	SubmitAttestation(ctx context.Context, envelope dsse.Envelope, tenant string) (claims.ConformanceClaim, error)
+	// Watch streams claim events after the given revision until the context is canceled.
+	Watch(ctx context.Context, afterRevision uint64) (<-chan claims.Event, error)
+}
+
+// Server serves the agent API.
+type Server struct {
+	backend    Backend
+	httpServer *http.Server
+	// unscoped allows requests without the tenant header to read every tenant.
+	unscoped bool
+	// shutdown is closed when the server shuts down to end open event streams.
+	shutdown chan struct{}
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithUnscopedAccess allows requests without the tenant header. They read every tenant
+// and may narrow to one with the tenant query parameter. Only allow it for agents with a
+// single tenant, or behind a proxy that sets the tenant header on every request that is
+// not from an administrator.
+func WithUnscopedAccess(allow bool) ServerOption {
+	return func(s *Server) {
+		s.unscoped = allow
+	}
+}
+
+// NewServer creates a Server listening on the given address.
+func NewServer(address string, backend Backend, opts ...ServerOption) *Server {
+	s := &Server{
+		backend:  backend,
+		shutdown: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/coverage", s.handleCoverage)
+	mux.HandleFunc("GET /v1/claims", s.handleClaims)
+	mux.HandleFunc("GET /v1/claims/watch", s.handleWatch)
+	mux.HandleFunc("GET /v1/claims/{id}", s.handleClaim)
+	mux.HandleFunc("PUT /v1/claims/{id}/hold", s.handleLegalHold(true))
+	mux.HandleFunc("DELETE /v1/claims/{id}/hold", s.handleLegalHold(false))
+	mux.HandleFunc("GET /v1/evidence/{ref}", s.handleEvidence)
+	mux.HandleFunc("POST /v1/attestations", s.handleAttestation)
+	mux.HandleFunc("GET /v1/snapshot", s.handleSnapshot)
+	mux.HandleFunc("GET /v1/waivers", s.handleWaivers)
+	s.httpServer = &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	s.httpServer.RegisterOnShutdown(func() { close(s.shutdown) })
+	return s
+}
+
+// ListenAndServe serves the API until Shutdown is called.
+func (s *Server) ListenAndServe() error {
+	log.Printf("Serving agent API on %s", s.httpServer.Addr)
+	if err := s.httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown gracefully stops the server.
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// TenantHeader scopes a request to a single tenant. A proxy in front of the API sets it
+// from the authenticated identity of the caller, so teams sharing an agent only see
+// their own claims. Requests without it are rejected unless unscoped access is allowed.
+const TenantHeader = "X-Tenant"
+
+var (
+	// errTenantScope is returned when a request asks for a tenant outside its scope.
+	errTenantScope = errors.New("tenant is outside the scope of the request")
+	// errTenantRequired is returned when a request without the tenant header is not
+	// allowed to read every tenant.
+	errTenantRequired = errors.New("the " + TenantHeader + " header is required")
+)
+
+// requestTenant returns the tenant the request is limited to, or an empty string for
+// every tenant. The tenant query parameter narrows unscoped requests but cannot select
+// a tenant other than the one in the tenant header. Requests without the header fail
+// unless unscoped is set.
+func requestTenant(r *http.Request, unscoped bool) (string, error) {
+	scope := r.Header.Get(TenantHeader)
+	tenant := r.URL.Query().Get("tenant")
+	switch {
+	case scope == "" && !unscoped:
+		return "", errTenantRequired
+	case scope == "":
+		return tenant, nil
+	case tenant != "" && tenant != scope:
+		return "", fmt.Errorf("%w: %s", errTenantScope, tenant)
+	default:
+		return scope, nil
+	}
+}
+
+// tenantScope returns the tenant the request is limited to, writing an error response
+// when the request has no tenant header or asks for another tenant.
+func (s *Server) tenantScope(w http.ResponseWriter, r *http.Request) (string, bool) {
+	tenant, err := requestTenant(r, s.unscoped)
+	switch {
+	case errors.Is(err, errTenantRequired):
+		writeError(w, http.StatusUnauthorized, err)
+		return "", false
+	case err != nil:
+		writeError(w, http.StatusForbidden, err)
+		return "", false
+	}
+	return tenant, true
+}
+
+// inScope reports whether the claim belongs to the tenant, or whether the tenant is empty.
+func inScope(claim claims.ConformanceClaim, tenant string) bool {
+	return tenant == "" || claim.TenantID() == tenant
+}
+
+// scopeClaims returns the claims belonging to the tenant.
+func scopeClaims(all []claims.ConformanceClaim, tenant string) []claims.ConformanceClaim {
+	if tenant == "" {
+		return all
+	}
+	scoped := make([]claims.ConformanceClaim, 0, len(all))
+	for _, claim := range all {
+		if inScope(claim, tenant) {
+			scoped = append(scoped, claim)
+		}
+	}
+	return scoped
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error writing API response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/in-toto/go-witness/dsse"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// fakeBackend serves stored claims and records the tenant of submitted attestations.
+type fakeBackend struct {
+	Backend
+	claims      map[string]claims.ConformanceClaim
+	attestedFor []string
+}
+
+func (b *fakeBackend) Claim(claimID string) (claims.ConformanceClaim, bool, error) {
+	claim, ok := b.claims[claimID]
+	return claim, ok, nil
+}
+
+func (b *fakeBackend) SubmitAttestation(_ context.Context, _ dsse.Envelope, tenant string) (claims.ConformanceClaim, error) {
+	b.attestedFor = append(b.attestedFor, tenant)
+	return claims.ConformanceClaim{ClaimID: "attested", Tenant: tenant}, nil
+}
+
+func newTestServer(unscoped bool) (*Server, *fakeBackend) {
+	backend := &fakeBackend{claims: map[string]claims.ConformanceClaim{
+		"a1": {ClaimID: "a1", Tenant: "team-a"},
+		"b1": {ClaimID: "b1", Tenant: "team-b"},
+	}}
+	return NewServer("", backend, WithUnscopedAccess(unscoped)), backend
+}
+
+func serve(s *Server, method, target, tenant, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if tenant != "" {
+		req.Header.Set(TenantHeader, tenant)
+	}
+	rec := httptest.NewRecorder()
+	s.httpServer.Handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestTenant(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		query    string
+		unscoped bool
+		want     string
+		wantErr  error
+	}{
+		{"header", "team-a", "", false, "team-a", nil},
+		{"header with matching query", "team-a", "team-a", false, "team-a", nil},
+		{"header with other query", "team-a", "team-b", false, "", errTenantScope},
+		{"header when unscoped is allowed", "team-a", "team-b", true, "", errTenantScope},
+		{"missing header", "", "", false, "", errTenantRequired},
+		{"missing header with query", "", "team-a", false, "", errTenantRequired},
+		{"unscoped", "", "", true, "", nil},
+		{"unscoped with query", "", "team-b", true, "team-b", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/claims?tenant="+tt.query, nil)
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			got, err := requestTenant(req, tt.unscoped)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimTenantScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		unscoped bool
+		tenant   string
+		claimID  string
+		want     int
+	}{
+		{"missing header is rejected", false, "", "a1", http.StatusUnauthorized},
+		{"own claim", false, "team-a", "a1", http.StatusOK},
+		{"other tenant's claim is not found", false, "team-a", "b1", http.StatusNotFound},
+		{"unscoped reads every tenant", true, "", "b1", http.StatusOK},
+		{"header scopes unscoped servers", true, "team-a", "b1", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestServer(tt.unscoped)
+			rec := serve(s, http.MethodGet, "/v1/claims/"+tt.claimID, tt.tenant, "")
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
+
+func TestAttestationTenantScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		unscoped bool
+		tenant   string
+		target   string
+		want     int
+		// attestedFor is the tenant passed to the backend, when the attestation is submitted.
+		attestedFor []string
+	}{
+		{"missing header is rejected", false, "", "/v1/attestations", http.StatusUnauthorized, nil},
+		{"query cannot select a tenant without the header", false, "", "/v1/attestations?tenant=team-b", http.StatusUnauthorized, nil},
+		{"header", false, "team-a", "/v1/attestations", http.StatusCreated, []string{"team-a"}},
+		{"header with other query", false, "team-a", "/v1/attestations?tenant=team-b", http.StatusForbidden, nil},
+		{"unscoped uses the agent's tenant", true, "", "/v1/attestations", http.StatusCreated, []string{""}},
+		{"unscoped with query", true, "", "/v1/attestations?tenant=team-b", http.StatusCreated, []string{"team-b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, backend := newTestServer(tt.unscoped)
+			rec := serve(s, http.MethodPost, tt.target, tt.tenant, "{}")
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if !slices.Equal(backend.attestedFor, tt.attestedFor) {
+				t.Errorf("attested for %q, want %q", backend.attestedFor, tt.attestedFor)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/snapshot"
+)
+
+// handleSnapshot returns a snapshot archive of every claim in the store, or of the
+// claims of the request's tenant.
+func (s *Server) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	allClaims, err := s.backend.Claims()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	allClaims = scopeClaims(allClaims, tenant)
+	// Buffer the archive so a failure is reported as an error instead of a truncated body.
+	var archive bytes.Buffer
+	manifest, err := snapshot.Write(&archive, allClaims)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+		"claims-"+manifest.CreatedAt.Format("20060102T150405Z")+".tar.gz"))
+	w.Header().Set("Snapshot-Digest", manifest.Digest)
+	w.Header().Set("Last-Modified", manifest.CreatedAt.Format(time.RFC1123))
+	_, _ = w.Write(archive.Bytes())
+}
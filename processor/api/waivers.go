@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// waiverStatus is a waiver with whether it currently applies.
+type waiverStatus struct {
+	mapping.Waiver
+	Active bool `json:"active"`
+}
+
+// handleWaivers lists the waivers in the active ruleset that apply to the request's
+// tenant. Expired waivers are included unless the active query parameter is true.
+// Waivers are registered in mapping rules files only; the API does not change them.
+func (s *Server) handleWaivers(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	activeOnly := r.URL.Query().Get("active") == "true"
+	now := time.Now()
+	waivers := []waiverStatus{}
+	for _, waiver := range s.backend.Ruleset().Waivers {
+		if tenant != "" && waiver.Tenant != "" && waiver.Tenant != tenant {
+			continue
+		}
+		active := waiver.Active(now)
+		if activeOnly && !active {
+			continue
+		}
+		waivers = append(waivers, waiverStatus{Waiver: waiver, Active: active})
+	}
+	writeJSON(w, http.StatusOK, waivers)
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// keepAliveInterval is how often an idle event stream sends a comment to keep proxies
+// from closing the connection.
+const keepAliveInterval = 15 * time.Second
+
+// handleWatch streams claim events as Server-Sent Events. Each event ID is the store
+// revision, so clients resume with the standard Last-Event-ID header or the revision
+// query parameter. Events for claims of other tenants are skipped.
+func (s *Server) handleWatch(w http.ResponseWriter, r *http.Request) {
+	tenant, ok := s.tenantScope(w, r)
+	if !ok {
+		return
+	}
+	after, err := watchRevision(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.shutdown:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	events, err := s.backend.Watch(ctx, after)
+	if errors.Is(err, claims.ErrResync) {
+		writeError(w, http.StatusGone, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if !inScope(event.Claim, tenant) {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding claim event %d: %v", event.Revision, err)
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Revision, event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func watchRevision(r *http.Request) (uint64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if revision := r.URL.Query().Get("revision"); revision != "" {
+		value = revision
+	}
+	if value == "" {
+		return 0, nil
+	}
+	revision, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid revision %q", value)
+	}
+	return revision, nil
+}
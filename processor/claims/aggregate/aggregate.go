@@ -0,0 +1,252 @@
+// Package aggregate combines method-level assessment results into a single
+// requirement-level verdict per resource.
+package aggregate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// Strategy defines how method results for the same requirement and resource are combined.
+type Strategy string
+
+const (
+	// AllMustPass is compliant only when every applicable method is compliant.
+	AllMustPass Strategy = "all-must-pass"
+	// AnyPass is compliant when at least one applicable method is compliant.
+	AnyPass Strategy = "any-pass"
+	// Majority is compliant when more applicable methods are compliant than not.
+	Majority Strategy = "majority"
+	// Weighted is compliant when the weight of compliant methods exceeds the threshold.
+	Weighted Strategy = "weighted"
+)
+
+// ParseStrategy returns the Strategy for the given name.
+func ParseStrategy(name string) (Strategy, error) {
+	switch s := Strategy(name); s {
+	case AllMustPass, AnyPass, Majority, Weighted:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown aggregation strategy %q", name)
+	}
+}
+
+// Verdict is the requirement-level result for a single resource of a tenant.
+type Verdict struct {
+	Tenant        string
+	CatalogID     string
+	RequirementID string
+	ResourceRef   string
+	Strategy      Strategy
+	Status        string
+	// Conflict is set when applicable methods disagree on the result.
+	Conflict bool
+	// Methods holds the latest status reported by each method.
+	Methods map[string]string
+	// ClaimIDs are the claims the verdict was computed from.
+	ClaimIDs []string
+}
+
+// Aggregator computes verdicts from conformance claims.
+type Aggregator struct {
+	strategy  Strategy
+	weights   map[string]float64
+	threshold float64
+}
+
+// Option configures an Aggregator.
+type Option func(a *Aggregator)
+
+// WithWeights sets per-method weights used by the Weighted strategy.
+// Methods without a weight count as 1.
+func WithWeights(weights map[string]float64) Option {
+	return func(a *Aggregator) {
+		a.weights = weights
+	}
+}
+
+// WithThreshold sets the fraction of applicable weight that must be compliant
+// for the Weighted strategy. The default is 0.5.
+func WithThreshold(threshold float64) Option {
+	return func(a *Aggregator) {
+		a.threshold = threshold
+	}
+}
+
+// New creates an Aggregator for the given strategy.
+func New(strategy Strategy, opts ...Option) (*Aggregator, error) {
+	if _, err := ParseStrategy(string(strategy)); err != nil {
+		return nil, err
+	}
+	a := &Aggregator{
+		strategy:  strategy,
+		weights:   make(map[string]float64),
+		threshold: 0.5,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a, nil
+}
+
+// Strategy returns the configured strategy.
+func (a *Aggregator) Strategy() Strategy {
+	return a.strategy
+}
+
+type verdictKey struct {
+	tenant        string
+	catalogID     string
+	requirementID string
+	resourceRef   string
+}
+
+type methodResult struct {
+	claim  claims.ConformanceClaim
+	status string
+}
+
+// Aggregate groups claims by tenant, catalog, requirement, and resource and computes a verdict for each group.
+// When a method reported more than once, only its newest result is considered.
+func (a *Aggregator) Aggregate(allClaims []claims.ConformanceClaim) []Verdict {
+	groups := make(map[verdictKey]map[string]methodResult)
+	for _, claim := range allClaims {
+		key := verdictKey{
+			tenant:        claim.TenantID(),
+			catalogID:     claim.CatalogID,
+			requirementID: claim.Assessment.RequirementID,
+			resourceRef:   claim.ResourceRef,
+		}
+		methods, ok := groups[key]
+		if !ok {
+			methods = make(map[string]methodResult)
+			groups[key] = methods
+		}
+		for _, method := range claim.Assessment.Methods {
+			if method.Result == nil {
+				continue
+			}
+			existing, ok := methods[method.Name]
+			if ok && existing.claim.Timestamp.After(claim.Timestamp) {
+				continue
+			}
+			methods[method.Name] = methodResult{claim: claim, status: string(method.Result.Status)}
+		}
+	}
+
+	verdicts := make([]Verdict, 0, len(groups))
+	for key, methods := range groups {
+		verdicts = append(verdicts, a.verdict(key, methods))
+	}
+	sort.Slice(verdicts, func(i, j int) bool {
+		if verdicts[i].Tenant != verdicts[j].Tenant {
+			return verdicts[i].Tenant < verdicts[j].Tenant
+		}
+		if verdicts[i].CatalogID != verdicts[j].CatalogID {
+			return verdicts[i].CatalogID < verdicts[j].CatalogID
+		}
+		if verdicts[i].RequirementID != verdicts[j].RequirementID {
+			return verdicts[i].RequirementID < verdicts[j].RequirementID
+		}
+		return verdicts[i].ResourceRef < verdicts[j].ResourceRef
+	})
+	return verdicts
+}
+
+func (a *Aggregator) verdict(key verdictKey, methods map[string]methodResult) Verdict {
+	v := Verdict{
+		Tenant:        key.tenant,
+		CatalogID:     key.catalogID,
+		RequirementID: key.requirementID,
+		ResourceRef:   key.resourceRef,
+		Strategy:      a.strategy,
+		Methods:       make(map[string]string, len(methods)),
+	}
+
+	seenClaims := make(map[string]struct{})
+	var passed, failed int
+	var unresolved []string
+	var passedWeight, applicableWeight float64
+	for name, result := range methods {
+		v.Methods[name] = result.status
+		if _, ok := seenClaims[result.claim.ClaimID]; !ok {
+			seenClaims[result.claim.ClaimID] = struct{}{}
+			v.ClaimIDs = append(v.ClaimIDs, result.claim.ClaimID)
+		}
+
+		switch {
+		case result.status == claims.StatusCompliant, result.status == claims.StatusWaived:
+			passed++
+			passedWeight += a.weight(name)
+			applicableWeight += a.weight(name)
+		case result.status == claims.StatusNotCompliant:
+			failed++
+			applicableWeight += a.weight(name)
+		case result.status == claims.StatusNotApplicable:
+		case claims.IsUnresolved(result.status):
+			unresolved = append(unresolved, result.status)
+		default:
+			unresolved = append(unresolved, claims.StatusUnknown)
+		}
+	}
+	sort.Strings(v.ClaimIDs)
+
+	v.Conflict = passed > 0 && failed > 0
+	if passed+failed == 0 {
+		if len(unresolved) > 0 {
+			v.Status = mostSevere(unresolved)
+		} else {
+			v.Status = claims.StatusNotApplicable
+		}
+		return v
+	}
+
+	var compliant bool
+	switch a.strategy {
+	case AllMustPass:
+		// An unresolved method prevents a pass but does not count as a failure.
+		if failed == 0 && len(unresolved) > 0 {
+			v.Status = mostSevere(unresolved)
+			return v
+		}
+		compliant = failed == 0
+	case AnyPass:
+		compliant = passed > 0
+	case Majority:
+		compliant = passed > failed
+	case Weighted:
+		compliant = applicableWeight > 0 && passedWeight/applicableWeight > a.threshold
+	}
+	if compliant {
+		v.Status = claims.StatusCompliant
+	} else {
+		v.Status = claims.StatusNotCompliant
+	}
+	return v
+}
+
+// mostSevere returns the unresolved status that most needs attention.
+func mostSevere(statuses []string) string {
+	severity := map[string]int{
+		claims.StatusError:       4,
+		claims.StatusStale:       3,
+		claims.StatusNeedsReview: 2,
+		claims.StatusUnknown:     1,
+	}
+	result := claims.StatusUnknown
+	for _, status := range statuses {
+		if severity[status] > severity[result] {
+			result = status
+		}
+	}
+	return result
+}
+
+func (a *Aggregator) weight(method string) float64 {
+	if w, ok := a.weights[method]; ok {
+		return w
+	}
+	return 1
+}
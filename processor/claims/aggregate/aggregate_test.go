@@ -0,0 +1,149 @@
+package aggregate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// claimWith returns a claim for requirement AC-1 on resource pod-a with one result per method.
+func claimWith(id string, age time.Duration, results map[string]string) claims.ConformanceClaim {
+	claim := claims.ConformanceClaim{
+		ClaimID:     id,
+		Timestamp:   epoch.Add(-age),
+		ResourceRef: "pod-a",
+		CatalogID:   "NIST-800-53",
+		Assessment:  layer4.Assessment{RequirementID: "AC-1"},
+	}
+	for name, status := range results {
+		method := layer4.AssessmentMethod{Name: name, Result: &layer4.AssessmentResult{}}
+		setStatus(&method.Result.Status, status)
+		claim.Assessment.Methods = append(claim.Assessment.Methods, method)
+	}
+	return claim
+}
+
+func setStatus[T ~string](dst *T, status string) {
+	*dst = T(status)
+}
+
+func TestAggregateStrategies(t *testing.T) {
+	pass, fail := claims.StatusCompliant, claims.StatusNotCompliant
+	tests := []struct {
+		name     string
+		strategy Strategy
+		opts     []Option
+		methods  map[string]string
+		status   string
+		conflict bool
+	}{
+		{"all-must-pass all pass", AllMustPass, nil, map[string]string{"OPA": pass, "Kyverno": pass}, pass, false},
+		{"all-must-pass one fails", AllMustPass, nil, map[string]string{"OPA": pass, "Kyverno": fail}, fail, true},
+		{"all-must-pass waived counts as pass", AllMustPass, nil, map[string]string{"OPA": pass, "Kyverno": claims.StatusWaived}, pass, false},
+		{"all-must-pass unresolved blocks pass", AllMustPass, nil, map[string]string{"OPA": pass, "Kyverno": claims.StatusNeedsReview}, claims.StatusNeedsReview, false},
+		{"all-must-pass failure outranks unresolved", AllMustPass, nil, map[string]string{"OPA": fail, "Kyverno": claims.StatusError}, fail, false},
+		{"any-pass one passes", AnyPass, nil, map[string]string{"OPA": pass, "Kyverno": fail}, pass, true},
+		{"any-pass none pass", AnyPass, nil, map[string]string{"OPA": fail, "Kyverno": fail}, fail, false},
+		{"majority more pass", Majority, nil, map[string]string{"a": pass, "b": pass, "c": fail}, pass, true},
+		{"majority tie fails", Majority, nil, map[string]string{"a": pass, "b": fail}, fail, true},
+		{"majority ignores not applicable", Majority, nil, map[string]string{"a": pass, "b": claims.StatusNotApplicable}, pass, false},
+		{"weighted heavy pass", Weighted, []Option{WithWeights(map[string]float64{"a": 3})}, map[string]string{"a": pass, "b": fail}, pass, true},
+		{"weighted heavy fail", Weighted, []Option{WithWeights(map[string]float64{"b": 3})}, map[string]string{"a": pass, "b": fail}, fail, true},
+		{"weighted threshold", Weighted, []Option{WithThreshold(0.8), WithWeights(map[string]float64{"a": 3})}, map[string]string{"a": pass, "b": fail}, fail, true},
+		{"only not applicable", AllMustPass, nil, map[string]string{"a": claims.StatusNotApplicable}, claims.StatusNotApplicable, false},
+		{"most severe unresolved", AnyPass, nil, map[string]string{"a": claims.StatusNeedsReview, "b": claims.StatusStale, "c": claims.StatusError}, claims.StatusError, false},
+		{"stale outranks needs review", Majority, nil, map[string]string{"a": claims.StatusNeedsReview, "b": claims.StatusStale}, claims.StatusStale, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregator, err := New(tt.strategy, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			verdicts := aggregator.Aggregate([]claims.ConformanceClaim{claimWith("c1", 0, tt.methods)})
+			if len(verdicts) != 1 {
+				t.Fatalf("got %d verdicts, want 1", len(verdicts))
+			}
+			v := verdicts[0]
+			if v.Status != tt.status {
+				t.Errorf("status = %s, want %s", v.Status, tt.status)
+			}
+			if v.Conflict != tt.conflict {
+				t.Errorf("conflict = %t, want %t", v.Conflict, tt.conflict)
+			}
+			if v.Strategy != tt.strategy {
+				t.Errorf("strategy = %s, want %s", v.Strategy, tt.strategy)
+			}
+		})
+	}
+}
+
+func TestAggregateUsesNewestMethodResult(t *testing.T) {
+	aggregator, err := New(AllMustPass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	older := claimWith("old", time.Hour, map[string]string{"OPA": claims.StatusNotCompliant})
+	newer := claimWith("new", 0, map[string]string{"OPA": claims.StatusCompliant})
+	for _, input := range [][]claims.ConformanceClaim{{older, newer}, {newer, older}} {
+		verdicts := aggregator.Aggregate(input)
+		if len(verdicts) != 1 {
+			t.Fatalf("got %d verdicts, want 1", len(verdicts))
+		}
+		if verdicts[0].Status != claims.StatusCompliant {
+			t.Errorf("status = %s, want %s", verdicts[0].Status, claims.StatusCompliant)
+		}
+		if !reflect.DeepEqual(verdicts[0].ClaimIDs, []string{"new"}) {
+			t.Errorf("claim IDs = %v, want [new]", verdicts[0].ClaimIDs)
+		}
+	}
+}
+
+func TestAggregateGroups(t *testing.T) {
+	aggregator, err := New(AllMustPass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := claimWith("a", 0, map[string]string{"OPA": claims.StatusCompliant})
+	otherResource := claimWith("b", 0, map[string]string{"OPA": claims.StatusNotCompliant})
+	otherResource.ResourceRef = "pod-b"
+	otherTenant := claimWith("c", 0, map[string]string{"OPA": claims.StatusNotCompliant})
+	otherTenant.Tenant = "team-b"
+	otherCatalog := claimWith("d", 0, map[string]string{"OPA": claims.StatusNotCompliant})
+	otherCatalog.CatalogID = "CIS"
+
+	verdicts := aggregator.Aggregate([]claims.ConformanceClaim{otherTenant, otherResource, base, otherCatalog})
+	var got []string
+	for _, v := range verdicts {
+		got = append(got, v.Tenant+"/"+v.CatalogID+"/"+v.ResourceRef+"="+v.Status)
+	}
+	want := []string{
+		"default/CIS/pod-a=NOT_COMPLIANT",
+		"default/NIST-800-53/pod-a=COMPLIANT",
+		"default/NIST-800-53/pod-b=NOT_COMPLIANT",
+		"team-b/NIST-800-53/pod-a=NOT_COMPLIANT",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("verdicts = %v, want %v", got, want)
+	}
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, name := range []string{"all-must-pass", "any-pass", "majority", "weighted"} {
+		if _, err := ParseStrategy(name); err != nil {
+			t.Errorf("ParseStrategy(%q): %v", name, err)
+		}
+	}
+	if _, err := ParseStrategy("unanimous"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+	if _, err := New("unanimous"); err == nil {
+		t.Error("expected New to reject an unknown strategy")
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"github.com/in-toto/go-witness/attestation"
 	"github.com/in-toto/go-witness/cryptoutil"
 	"github.com/invopop/jsonschema"
-	"github.com/revanite-io/sci/layer4"
 
 	"github.com/jpower432/shiny-journey/processor/claims"
 	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
 )
 
 const Name = "conformance-claim"
@@ -20,13 +20,13 @@ const RunType = attestation.VerifyRunType
 type AssessmentAttestor struct {
 	Claim       *claims.ConformanceClaim
 	rawEvidence evidence.RawEvidence
-	plan        layer4.Layer4
+	ruleset     *mapping.Ruleset
 	evidenceRef string
 }
 
-func NewAttestor(evidence evidence.RawEvidence, rawEnvRef string, plan layer4.Layer4) *AssessmentAttestor {
+func NewAttestor(evidence evidence.RawEvidence, rawEnvRef string, ruleset *mapping.Ruleset) *AssessmentAttestor {
 	return &AssessmentAttestor{
-		plan:        plan,
+		ruleset:     ruleset,
 		rawEvidence: evidence,
 		evidenceRef: rawEnvRef,
 	}
@@ -49,7 +49,10 @@ func (a *AssessmentAttestor) RunType() attestation.RunType {
 }
 
 func (a *AssessmentAttestor) Attest(ctx *attestation.AttestationContext) error {
-	claim := claims.NewFromEvidence(a.rawEvidence, a.evidenceRef, a.plan)
+	claim, err := claims.NewFromEvidence(a.rawEvidence, a.evidenceRef, a.ruleset)
+	if err != nil {
+		return err
+	}
 	a.Claim = claim
 	return nil
 }
@@ -61,7 +64,7 @@ func (a *AssessmentAttestor) Subjects() map[string]cryptoutil.DigestSet {
 }
 
 func (a *AssessmentAttestor) Schema() *jsonschema.Schema {
-	return jsonschema.Reflect(a.Claim)
+	return claims.Schema()
 }
 
 func (a *AssessmentAttestor) MarshalJSON() ([]byte, error) {
@@ -69,9 +72,10 @@ func (a *AssessmentAttestor) MarshalJSON() ([]byte, error) {
 }
 
 func (a *AssessmentAttestor) UnmarshalJSON(data []byte) error {
-	if err := json.Unmarshal(data, a.Claim); err != nil {
+	claim := &claims.ConformanceClaim{}
+	if err := json.Unmarshal(data, claim); err != nil {
 		return err
 	}
-
+	a.Claim = claim
 	return nil
 }
@@ -2,32 +2,56 @@ package auditlog
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
-	"github.com/revanite-io/sci/layer4"
 	"go.opentelemetry.io/otel/log"
 	"go.opentelemetry.io/otel/log/global"
 
 	"github.com/jpower432/shiny-journey/processor/claims"
-	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/drift"
 )
 
-// LogClaim logs the event to the global logger
-func LogClaim(ctx context.Context, rawEnv evidence.RawEvidence, evRef string, plan layer4.Layer4) (*claims.ConformanceClaim, error) {
+// Emit logs an existing claim to the global logger.
+func Emit(ctx context.Context, claim *claims.ConformanceClaim) error {
 	logger := global.Logger("agent-logger")
-	claim := claims.NewFromEvidence(rawEnv, evRef, plan)
 	record := log.Record{}
 	record.SetEventName(claim.Summary)
 	record.SetTimestamp(claim.Timestamp)
 	record.SetObservedTimestamp(time.Now())
+	record.AddAttributes(log.String("tenant", claim.TenantID()))
 
 	jsonData, err := claim.MarshalJSON()
 	if err != nil {
-		return claim, err
+		return err
 	}
 	claimValue := log.BytesValue(jsonData)
 	record.SetBody(claimValue)
 
 	logger.Emit(ctx, record)
-	return claim, nil
+	return nil
+}
+
+// EmitTransition logs a verdict transition to the global logger.
+func EmitTransition(ctx context.Context, transition drift.Transition) error {
+	logger := global.Logger("agent-logger")
+	record := log.Record{}
+	record.SetEventName(fmt.Sprintf("Requirement %s on resource '%s' changed from %s to %s.",
+		transition.RequirementID, transition.ResourceRef, transition.From, transition.To))
+	record.SetTimestamp(transition.Timestamp)
+	record.SetObservedTimestamp(time.Now())
+	record.AddAttributes(
+		log.String("transition_kind", string(transition.Kind)),
+		log.String("tenant", transition.Tenant),
+	)
+
+	jsonData, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+	record.SetBody(log.BytesValue(jsonData))
+
+	logger.Emit(ctx, record)
+	return nil
 }
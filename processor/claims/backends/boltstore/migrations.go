@@ -0,0 +1,268 @@
+package boltstore
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// migration upgrades the database by one schema version.
+type migration struct {
+	description string
+	apply       func(tx *bolt.Tx) error
+}
+
+// Migrations use their own copies of bucket names and key formats, as of the schema
+// version that introduced them, so later changes to the store cannot alter how an
+// older database is upgraded.
+var (
+	v1ClaimsBucket  = []byte("claims")
+	v2TimeBucket    = []byte("claims_by_time")
+	v4CurrentBucket = []byte("current")
+	v5EventsBucket  = []byte("events")
+	v6TenantBucket  = []byte("claims_by_tenant")
+)
+
+// v3Indexes are the secondary indexes added by schema version 3.
+var v3Indexes = []struct {
+	bucket []byte
+	value  func(claim claims.ConformanceClaim) string
+}{
+	{[]byte("claims_by_requirement"), func(c claims.ConformanceClaim) string { return c.Assessment.RequirementID }},
+	{[]byte("claims_by_resource"), func(c claims.ConformanceClaim) string { return c.ResourceRef }},
+	{[]byte("claims_by_control"), func(c claims.ConformanceClaim) string { return c.ControlID }},
+	{[]byte("claims_by_catalog"), func(c claims.ConformanceClaim) string { return c.CatalogID }},
+}
+
+// v2TimeKey is the time index key of schema version 2: the big-endian timestamp with
+// the sign bit flipped, followed by the claim ID.
+func v2TimeKey(t time.Time, claimID string) []byte {
+	key := make([]byte, 8, 8+len(claimID))
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano())^(1<<63))
+	return append(key, claimID...)
+}
+
+// v3IndexKey is the secondary index key of schema version 3: the field value, a zero
+// byte, and the time index key.
+func v3IndexKey(value string, timeKey []byte) []byte {
+	return append(append([]byte(value), 0), timeKey...)
+}
+
+// v4PostureKey is the posture key of schema version 4, before keys included the tenant.
+func v4PostureKey(key claims.PostureKey) []byte {
+	return []byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s", key.CatalogID, key.RequirementID, key.ResourceRef, key.Method))
+}
+
+// v6PostureKey is the posture key of schema version 6, scoped to the tenant.
+func v6PostureKey(key claims.PostureKey) []byte {
+	return []byte(fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", key.Tenant, key.CatalogID, key.RequirementID, key.ResourceRef, key.Method))
+}
+
+// migrations are applied in order. The schema version of a database is the number of
+// migrations applied to it, so existing entries must never be reordered or removed.
+var migrations = []migration{
+	{
+		description: "create claims bucket",
+		apply: func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(v1ClaimsBucket)
+			return err
+		},
+	},
+	{
+		description: "index claims by timestamp",
+		apply: func(tx *bolt.Tx) error {
+			timeB, err := tx.CreateBucketIfNotExists(v2TimeBucket)
+			if err != nil {
+				return err
+			}
+			return forEachClaim(tx, func(claim claims.ConformanceClaim) error {
+				return timeB.Put(v2TimeKey(claim.Timestamp, claim.ClaimID), nil)
+			})
+		},
+	},
+	{
+		description: "index claims by catalog, control, requirement, and resource",
+		apply: func(tx *bolt.Tx) error {
+			for _, idx := range v3Indexes {
+				if _, err := tx.CreateBucketIfNotExists(idx.bucket); err != nil {
+					return err
+				}
+			}
+			return forEachClaim(tx, func(claim claims.ConformanceClaim) error {
+				timeKey := v2TimeKey(claim.Timestamp, claim.ClaimID)
+				for _, idx := range v3Indexes {
+					if err := tx.Bucket(idx.bucket).Put(v3IndexKey(idx.value(claim), timeKey), nil); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	},
+	{
+		description: "track the current claim for each posture key",
+		apply: func(tx *bolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(v4CurrentBucket); err != nil {
+				return err
+			}
+			// Replay claims oldest first so each one supersedes the claims before it.
+			var ids [][]byte
+			cursor := tx.Bucket(v2TimeBucket).Cursor()
+			for k, _ := cursor.First(); k != nil; k, _ = cursor.Next() {
+				ids = append(ids, append([]byte(nil), k[8:]...))
+			}
+			posture := v4Posture{tx}
+			for _, id := range ids {
+				claim, ok, err := posture.Claim(string(id))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					return fmt.Errorf("time index references missing claim %s", id)
+				}
+				superseded, err := claims.UpdatePosture(posture, &claim)
+				if err != nil {
+					return err
+				}
+				for _, old := range append(superseded, claim) {
+					data, err := json.Marshal(old)
+					if err != nil {
+						return fmt.Errorf("failed to encode claim %s: %w", old.ClaimID, err)
+					}
+					if err := tx.Bucket(v1ClaimsBucket).Put([]byte(old.ClaimID), data); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		description: "record claim events for watches",
+		apply: func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(v5EventsBucket)
+			return err
+		},
+	},
+	{
+		description: "scope posture keys and index claims by tenant",
+		apply: func(tx *bolt.Tx) error {
+			if err := rekeyPosture(tx); err != nil {
+				return err
+			}
+			tenants, err := tx.CreateBucketIfNotExists(v6TenantBucket)
+			if err != nil {
+				return err
+			}
+			return forEachClaim(tx, func(claim claims.ConformanceClaim) error {
+				return tenants.Put(v3IndexKey(claim.TenantID(), v2TimeKey(claim.Timestamp, claim.ClaimID)), nil)
+			})
+		},
+	},
+}
+
+// forEachClaim decodes every claim in the claims bucket.
+func forEachClaim(tx *bolt.Tx, fn func(claim claims.ConformanceClaim) error) error {
+	return tx.Bucket(v1ClaimsBucket).ForEach(func(id, data []byte) error {
+		var claim claims.ConformanceClaim
+		if err := json.Unmarshal(data, &claim); err != nil {
+			return fmt.Errorf("failed to decode claim %s: %w", id, err)
+		}
+		return fn(claim)
+	})
+}
+
+// rekeyPosture moves current posture entries recorded with schema version 4 keys to the
+// tenant-scoped keys of schema version 6.
+func rekeyPosture(tx *bolt.Tx) error {
+	current := tx.Bucket(v4CurrentBucket)
+	posture := v4Posture{tx}
+	var untenanted [][]byte
+	rekeyed := make(map[string][]byte)
+	err := current.ForEach(func(k, id []byte) error {
+		claim, ok, err := posture.Claim(string(id))
+		if err != nil || !ok {
+			return err
+		}
+		for _, key := range claim.PostureKeys() {
+			if bytes.Equal(k, v4PostureKey(key)) {
+				untenanted = append(untenanted, append([]byte(nil), k...))
+				rekeyed[string(v6PostureKey(key))] = append([]byte(nil), id...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range untenanted {
+		if err := current.Delete(k); err != nil {
+			return err
+		}
+	}
+	for k, id := range rekeyed {
+		if err := current.Put([]byte(k), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// v4Posture is the PostureIndex of schema version 4.
+type v4Posture struct {
+	tx *bolt.Tx
+}
+
+func (p v4Posture) CurrentID(key claims.PostureKey) (string, error) {
+	return string(p.tx.Bucket(v4CurrentBucket).Get(v4PostureKey(key))), nil
+}
+
+func (p v4Posture) SetCurrent(key claims.PostureKey, claimID string) error {
+	return p.tx.Bucket(v4CurrentBucket).Put(v4PostureKey(key), []byte(claimID))
+}
+
+func (p v4Posture) Claim(claimID string) (claims.ConformanceClaim, bool, error) {
+	var claim claims.ConformanceClaim
+	data := p.tx.Bucket(v1ClaimsBucket).Get([]byte(claimID))
+	if data == nil {
+		return claim, false, nil
+	}
+	if err := json.Unmarshal(data, &claim); err != nil {
+		return claim, false, fmt.Errorf("failed to decode claim %s: %w", claimID, err)
+	}
+	return claim, true, nil
+}
+
+// schemaVersion is the schema version this package reads and writes.
+var schemaVersion = len(migrations)
+
+// migrate applies any migrations the database has not seen yet.
+func migrate(tx *bolt.Tx) error {
+	meta, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return err
+	}
+	version := 0
+	if raw := meta.Get(schemaVersionKey); raw != nil {
+		version, err = strconv.Atoi(string(raw))
+		if err != nil {
+			return fmt.Errorf("invalid schema version %q: %w", raw, err)
+		}
+	}
+	if version > schemaVersion {
+		return fmt.Errorf("schema version %d is newer than the supported version %d", version, schemaVersion)
+	}
+	for i := version; i < schemaVersion; i++ {
+		if err := migrations[i].apply(tx); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", i+1, migrations[i].description, err)
+		}
+	}
+	return meta.Put(schemaVersionKey, []byte(strconv.Itoa(schemaVersion)))
+}
@@ -0,0 +1,167 @@
+package boltstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// createDatabase writes a database at the schema version. The claims are stored once
+// the claims bucket exists, so the remaining migrations up to the version index them
+// as they would have been indexed at the time. setup may then adjust the database.
+func createDatabase(t *testing.T, version int, stored []claims.ConformanceClaim, setup func(tx *bolt.Tx) error) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claims.db")
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+		if err := migrations[0].apply(tx); err != nil {
+			return err
+		}
+		for _, claim := range stored {
+			if err := putRaw(tx, claim); err != nil {
+				return err
+			}
+		}
+		for _, m := range migrations[1:version] {
+			if err := m.apply(tx); err != nil {
+				return err
+			}
+		}
+		if setup != nil {
+			if err := setup(tx); err != nil {
+				return err
+			}
+		}
+		return meta.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// putRaw stores the claim in the claims bucket without indexing it.
+func putRaw(tx *bolt.Tx, claim claims.ConformanceClaim) error {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(v1ClaimsBucket).Put([]byte(claim.ClaimID), data)
+}
+
+func TestMigrations(t *testing.T) {
+	other := newClaim("c3", 2, "OPA")
+	other.ResourceRef = "pod-b"
+	stored := []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), newClaim("c2", 1, "OPA"), other}
+
+	for version := 1; version < schemaVersion; version++ {
+		t.Run("from version "+strconv.Itoa(version), func(t *testing.T) {
+			path := createDatabase(t, version, stored, nil)
+			store, err := Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			assertMigrated(t, store)
+		})
+	}
+}
+
+func assertMigrated(t *testing.T, store *Store) {
+	t.Helper()
+	var version string
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		version = string(tx.Bucket(metaBucket).Get(schemaVersionKey))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if version != strconv.Itoa(schemaVersion) {
+		t.Errorf("schema version = %s, want %d", version, schemaVersion)
+	}
+
+	current, err := store.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sortedIDs(current); !slices.Equal(got, []string{"c2", "c3"}) {
+		t.Errorf("current = %v, want [c2 c3]", got)
+	}
+	for _, q := range []claims.Query{{ResourceRef: "pod-b"}, {Tenant: "default", ResourceRef: "pod-b"}} {
+		result, err := store.Query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ids(result.Claims); !slices.Equal(got, []string{"c3"}) {
+			t.Errorf("query %+v = %v, want [c3]", q, got)
+		}
+	}
+	result, err := store.Query(claims.Query{Tenant: "default"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(result.Claims); !slices.Equal(got, []string{"c1", "c2", "c3"}) {
+		t.Errorf("tenant query = %v, want [c1 c2 c3]", got)
+	}
+
+	// New claims supersede migrated ones under the current posture keys.
+	mustAdd(t, store, newClaim("c4", 3, "OPA"))
+	current, err = store.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sortedIDs(current); !slices.Equal(got, []string{"c3", "c4"}) {
+		t.Errorf("current after add = %v, want [c3 c4]", got)
+	}
+}
+
+func TestMigrationRekeysUntenantedPosture(t *testing.T) {
+	claim := newClaim("c1", 0, "OPA", "Kyverno")
+	path := createDatabase(t, 5, []claims.ConformanceClaim{claim}, func(tx *bolt.Tx) error {
+		// Schema version 4 recorded posture keys without the tenant.
+		for _, key := range claim.PostureKeys() {
+			if tx.Bucket(v4CurrentBucket).Get(v4PostureKey(key)) == nil {
+				return fmt.Errorf("no schema version 4 posture key for method %s", key.Method)
+			}
+		}
+		return nil
+	})
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var keys []string
+	if err := store.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(currentBucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, key := range claim.PostureKeys() {
+		want = append(want, key.String())
+	}
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("posture keys = %q, want %q", keys, want)
+	}
+}
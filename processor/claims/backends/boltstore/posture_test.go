@@ -0,0 +1,86 @@
+package boltstore
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+func TestPosture(t *testing.T) {
+	otherTenant := newClaim("t1", 5, "OPA")
+	otherTenant.Tenant = "team-a"
+
+	tests := []struct {
+		name       string
+		add        []claims.ConformanceClaim
+		current    []string
+		superseded map[string]string
+	}{
+		{
+			name:       "newer claim supersedes",
+			add:        []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), newClaim("c2", 1, "OPA")},
+			current:    []string{"c2"},
+			superseded: map[string]string{"c1": "c2"},
+		},
+		{
+			name:       "late arrival is superseded on add",
+			add:        []claims.ConformanceClaim{newClaim("c2", 1, "OPA"), newClaim("c1", 0, "OPA")},
+			current:    []string{"c2"},
+			superseded: map[string]string{"c1": "c2"},
+		},
+		{
+			name:    "methods are tracked separately",
+			add:     []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), newClaim("c2", 1, "Kyverno")},
+			current: []string{"c1", "c2"},
+		},
+		{
+			name:    "claim stays current for any of its methods",
+			add:     []claims.ConformanceClaim{newClaim("c1", 0, "OPA", "Kyverno"), newClaim("c2", 1, "OPA")},
+			current: []string{"c1", "c2"},
+		},
+		{
+			name:       "claim is superseded once all its methods are",
+			add:        []claims.ConformanceClaim{newClaim("c1", 0, "OPA", "Kyverno"), newClaim("c2", 1, "OPA"), newClaim("c3", 2, "Kyverno")},
+			current:    []string{"c2", "c3"},
+			superseded: map[string]string{"c1": "c3"},
+		},
+		{
+			name:    "tenants never supersede each other",
+			add:     []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), otherTenant},
+			current: []string{"c1", "t1"},
+		},
+		{
+			name:    "re-adding a claim keeps it current",
+			add:     []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), newClaim("c1", 0, "OPA")},
+			current: []string{"c1"},
+		},
+	}
+	for _, tt := range tests {
+		for _, storeName := range []string{"memory", "bolt"} {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				var store claims.Store = claims.NewMemoryStore()
+				if storeName == "bolt" {
+					store, _ = openStore(t)
+				}
+				mustAdd(t, store, tt.add...)
+				current, err := store.Current()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := sortedIDs(current); !slices.Equal(got, tt.current) {
+					t.Errorf("current = %v, want %v", got, tt.current)
+				}
+				all, err := store.GetClaims()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, claim := range all {
+					if want := tt.superseded[claim.ClaimID]; claim.SupersededBy != want {
+						t.Errorf("%s superseded by %q, want %q", claim.ClaimID, claim.SupersededBy, want)
+					}
+				}
+			})
+		}
+	}
+}
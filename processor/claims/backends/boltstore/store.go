@@ -0,0 +1,473 @@
+// Package boltstore persists conformance claims in an embedded bbolt database so the
+// reported posture survives agent restarts.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+var (
+	metaBucket   = []byte("meta")
+	claimsBucket = []byte("claims")
+	// timeBucket indexes claim IDs by timestamp. Keys are the big-endian timestamp
+	// followed by the claim ID so cursor order is chronological.
+	timeBucket = []byte("claims_by_time")
+	// currentBucket maps each posture key to the ID of its current claim.
+	currentBucket = []byte("current")
+	// eventsBucket holds the recent event history keyed by big-endian revision.
+	eventsBucket = []byte("events")
+	// tenantIndexBucket indexes claim IDs by tenant.
+	tenantIndexBucket = []byte("claims_by_tenant")
+
+	schemaVersionKey = []byte("schema_version")
+)
+
+// index is a secondary index of claim IDs by one claim field. Keys are the field value,
+// a zero byte, and the time index key, so each value's claims are chronological.
+type index struct {
+	bucket []byte
+	value  func(claim claims.ConformanceClaim) string
+	filter func(q claims.Query) string
+}
+
+// indexes are listed from most to least selective; queries scan the first one they filter on.
+var indexes = []index{
+	{
+		bucket: []byte("claims_by_requirement"),
+		value:  func(c claims.ConformanceClaim) string { return c.Assessment.RequirementID },
+		filter: func(q claims.Query) string { return q.RequirementID },
+	},
+	{
+		bucket: []byte("claims_by_resource"),
+		value:  func(c claims.ConformanceClaim) string { return c.ResourceRef },
+		filter: func(q claims.Query) string { return q.ResourceRef },
+	},
+	{
+		bucket: []byte("claims_by_control"),
+		value:  func(c claims.ConformanceClaim) string { return c.ControlID },
+		filter: func(q claims.Query) string { return q.ControlID },
+	},
+	{
+		bucket: []byte("claims_by_catalog"),
+		value:  func(c claims.ConformanceClaim) string { return c.CatalogID },
+		filter: func(q claims.Query) string { return q.CatalogID },
+	},
+	{
+		bucket: tenantIndexBucket,
+		value:  func(c claims.ConformanceClaim) string { return c.TenantID() },
+		filter: func(q claims.Query) string { return q.Tenant },
+	},
+}
+
+var _ claims.Store = (*Store)(nil)
+
+// Store is a claims.Store backed by a bbolt database file.
+type Store struct {
+	db *bolt.DB
+	// watchMu serializes writes with watch subscriptions so a watcher neither misses
+	// nor repeats events between its replay and the live stream.
+	watchMu sync.Mutex
+	feed    claims.Feed
+}
+
+// Open opens the database at path, creating it if needed, and migrates it to the
+// current schema version.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open claim store %s: %w", path, err)
+	}
+	if err := db.Update(migrate); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate claim store %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Add(claim claims.ConformanceClaim) error {
+	return s.update(func(tx *bolt.Tx) ([]claims.Event, error) {
+		return add(tx, claim)
+	})
+}
+
+// update runs fn in a write transaction and publishes the events it recorded once the
+// transaction commits.
+func (s *Store) update(fn func(tx *bolt.Tx) ([]claims.Event, error)) error {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	var events []claims.Event
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		events, err = fn(tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.feed.Publish(events...)
+	return nil
+}
+
+func add(tx *bolt.Tx, claim claims.ConformanceClaim) ([]claims.Event, error) {
+	if existing := tx.Bucket(claimsBucket).Get([]byte(claim.ClaimID)); existing != nil {
+		var old claims.ConformanceClaim
+		if err := json.Unmarshal(existing, &old); err != nil {
+			return nil, fmt.Errorf("failed to decode claim %s: %w", claim.ClaimID, err)
+		}
+		if err := unindex(tx, old); err != nil {
+			return nil, err
+		}
+	}
+	superseded, err := claims.UpdatePosture(txPosture{tx}, &claim)
+	if err != nil {
+		return nil, err
+	}
+	if err := putClaim(tx, claim); err != nil {
+		return nil, err
+	}
+	if err := putIndexes(tx, claim); err != nil {
+		return nil, err
+	}
+	event, err := appendEvent(tx, claims.EventAdded, claim)
+	if err != nil {
+		return nil, err
+	}
+	events := []claims.Event{event}
+	for _, old := range superseded {
+		if err := putClaim(tx, old); err != nil {
+			return nil, err
+		}
+		event, err := appendEvent(tx, claims.EventSuperseded, old)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func putClaim(tx *bolt.Tx, claim claims.ConformanceClaim) error {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return fmt.Errorf("failed to encode claim %s: %w", claim.ClaimID, err)
+	}
+	return tx.Bucket(claimsBucket).Put([]byte(claim.ClaimID), data)
+}
+
+func getClaim(tx *bolt.Tx, claimID []byte) (claims.ConformanceClaim, bool, error) {
+	var claim claims.ConformanceClaim
+	data := tx.Bucket(claimsBucket).Get(claimID)
+	if data == nil {
+		return claim, false, nil
+	}
+	if err := json.Unmarshal(data, &claim); err != nil {
+		return claim, false, fmt.Errorf("failed to decode claim %s: %w", claimID, err)
+	}
+	return claim, true, nil
+}
+
+func (s *Store) Get(claimID string) (claims.ConformanceClaim, bool, error) {
+	var claim claims.ConformanceClaim
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		claim, found, err = getClaim(tx, []byte(claimID))
+		return err
+	})
+	return claim, found, err
+}
+
+func (s *Store) GetClaims() ([]claims.ConformanceClaim, error) {
+	var all []claims.ConformanceClaim
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(claimsBucket).ForEach(func(id, data []byte) error {
+			var claim claims.ConformanceClaim
+			if err := json.Unmarshal(data, &claim); err != nil {
+				return fmt.Errorf("failed to decode claim %s: %w", id, err)
+			}
+			all = append(all, claim)
+			return nil
+		})
+	})
+	return all, err
+}
+
+func (s *Store) Current() ([]claims.ConformanceClaim, error) {
+	var current []claims.ConformanceClaim
+	err := s.db.View(func(tx *bolt.Tx) error {
+		seen := make(map[string]bool)
+		return tx.Bucket(currentBucket).ForEach(func(_, id []byte) error {
+			if seen[string(id)] {
+				return nil
+			}
+			seen[string(id)] = true
+			claim, ok, err := getClaim(tx, id)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("current posture references missing claim %s", id)
+			}
+			current = append(current, claim)
+			return nil
+		})
+	})
+	return current, err
+}
+
+// Query narrows candidates with the most selective index the query filters on, or the
+// time index otherwise, before filtering and paging them.
+func (s *Store) Query(q claims.Query) (claims.QueryResult, error) {
+	if err := q.Validate(); err != nil {
+		return claims.QueryResult{}, err
+	}
+	var candidates []claims.ConformanceClaim
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket, prefix := timeBucket, []byte(nil)
+		for _, idx := range indexes {
+			if value := idx.filter(q); value != "" {
+				bucket, prefix = idx.bucket, indexPrefix(value)
+				break
+			}
+		}
+		return scan(tx.Bucket(bucket), prefix, q.Start, q.End, func(id []byte) error {
+			claim, ok, err := getClaim(tx, id)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return fmt.Errorf("index references missing claim %s", id)
+			}
+			candidates = append(candidates, claim)
+			return nil
+		})
+	})
+	if err != nil {
+		return claims.QueryResult{}, err
+	}
+	return claims.Page(q, candidates)
+}
+
+func (s *Store) Delete(claimIDs ...string) ([]string, error) {
+	var deleted []string
+	err := s.update(func(tx *bolt.Tx) ([]claims.Event, error) {
+		deleted = nil
+		var events []claims.Event
+		for _, claimID := range claimIDs {
+			claim, ok, err := getClaim(tx, []byte(claimID))
+			if err != nil {
+				return nil, err
+			}
+			if !ok || claim.Current() || claim.LegalHold {
+				continue
+			}
+			if err := unindex(tx, claim); err != nil {
+				return nil, err
+			}
+			if err := tx.Bucket(claimsBucket).Delete([]byte(claimID)); err != nil {
+				return nil, err
+			}
+			deleted = append(deleted, claimID)
+			event, err := appendEvent(tx, claims.EventExpired, claim)
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, event)
+		}
+		return events, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func (s *Store) SetLegalHold(claimID string, hold bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		claim, ok, err := getClaim(tx, []byte(claimID))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("claim %s: %w", claimID, claims.ErrNotFound)
+		}
+		claim.LegalHold = hold
+		return putClaim(tx, claim)
+	})
+}
+
+func (s *Store) Watch(ctx context.Context, afterRevision uint64) (<-chan claims.Event, error) {
+	s.watchMu.Lock()
+	defer s.watchMu.Unlock()
+	var replay []claims.Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		replay, err = eventsAfter(tx, afterRevision)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.feed.Subscribe(ctx, replay), nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// timePrefix encodes t so that byte order matches chronological order. The sign bit is
+// flipped so timestamps before the Unix epoch sort first.
+func timePrefix(t time.Time) []byte {
+	prefix := make([]byte, 8)
+	binary.BigEndian.PutUint64(prefix, uint64(t.UnixNano())^(1<<63))
+	return prefix
+}
+
+func timeKey(t time.Time, claimID string) []byte {
+	return append(timePrefix(t), claimID...)
+}
+
+func indexPrefix(value string) []byte {
+	return append([]byte(value), 0)
+}
+
+// scan calls fn with the claim ID of each key under prefix whose timestamp is within
+// [start, end), in chronological order.
+func scan(bucket *bolt.Bucket, prefix []byte, start, end time.Time, fn func(id []byte) error) error {
+	cursor := bucket.Cursor()
+	seek := prefix
+	if !start.IsZero() {
+		seek = append(append([]byte(nil), prefix...), timePrefix(start)...)
+	}
+	var endPrefix []byte
+	if !end.IsZero() {
+		endPrefix = timePrefix(end)
+	}
+	for k, _ := cursor.Seek(seek); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		key := k[len(prefix):]
+		if endPrefix != nil && bytes.Compare(key[:8], endPrefix) >= 0 {
+			break
+		}
+		if err := fn(key[8:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putIndexes adds the claim to the time index and every secondary index.
+func putIndexes(tx *bolt.Tx, claim claims.ConformanceClaim) error {
+	key := timeKey(claim.Timestamp, claim.ClaimID)
+	if err := tx.Bucket(timeBucket).Put(key, nil); err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := tx.Bucket(idx.bucket).Put(append(indexPrefix(idx.value(claim)), key...), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unindex removes the claim from every index.
+func unindex(tx *bolt.Tx, claim claims.ConformanceClaim) error {
+	key := timeKey(claim.Timestamp, claim.ClaimID)
+	if err := tx.Bucket(timeBucket).Delete(key); err != nil {
+		return err
+	}
+	for _, idx := range indexes {
+		if err := tx.Bucket(idx.bucket).Delete(append(indexPrefix(idx.value(claim)), key...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendEvent records an event with the next revision and trims the history to
+// claims.MaxEventHistory events.
+func appendEvent(tx *bolt.Tx, eventType claims.EventType, claim claims.ConformanceClaim) (claims.Event, error) {
+	bucket := tx.Bucket(eventsBucket)
+	revision, err := bucket.NextSequence()
+	if err != nil {
+		return claims.Event{}, err
+	}
+	event := claims.Event{Revision: revision, Type: eventType, Claim: claim}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return claims.Event{}, fmt.Errorf("failed to encode event %d: %w", revision, err)
+	}
+	if err := bucket.Put(revisionKey(revision), data); err != nil {
+		return claims.Event{}, err
+	}
+	if revision > claims.MaxEventHistory {
+		cutoff := revisionKey(revision - claims.MaxEventHistory)
+		var expired [][]byte
+		cursor := bucket.Cursor()
+		for k, _ := cursor.First(); k != nil && bytes.Compare(k, cutoff) <= 0; k, _ = cursor.Next() {
+			expired = append(expired, append([]byte(nil), k...))
+		}
+		for _, k := range expired {
+			if err := bucket.Delete(k); err != nil {
+				return claims.Event{}, err
+			}
+		}
+	}
+	return event, nil
+}
+
+// eventsAfter returns the recorded events after the revision. A zero revision replays nothing.
+func eventsAfter(tx *bolt.Tx, revision uint64) ([]claims.Event, error) {
+	bucket := tx.Bucket(eventsBucket)
+	if revision > bucket.Sequence() {
+		return nil, claims.ErrRevisionAhead
+	}
+	if revision == 0 || revision == bucket.Sequence() {
+		return nil, nil
+	}
+	cursor := bucket.Cursor()
+	if first, _ := cursor.First(); first == nil || binary.BigEndian.Uint64(first) > revision+1 {
+		return nil, claims.ErrRevisionCompacted
+	}
+	var events []claims.Event
+	for k, data := cursor.Seek(revisionKey(revision + 1)); k != nil; k, data = cursor.Next() {
+		var event claims.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode event %d: %w", binary.BigEndian.Uint64(k), err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func revisionKey(revision uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, revision)
+	return key
+}
+
+// txPosture is the PostureIndex of a Store within a write transaction.
+type txPosture struct {
+	tx *bolt.Tx
+}
+
+func (p txPosture) CurrentID(key claims.PostureKey) (string, error) {
+	return string(p.tx.Bucket(currentBucket).Get([]byte(key.String()))), nil
+}
+
+func (p txPosture) SetCurrent(key claims.PostureKey, claimID string) error {
+	return p.tx.Bucket(currentBucket).Put([]byte(key.String()), []byte(claimID))
+}
+
+func (p txPosture) Claim(claimID string) (claims.ConformanceClaim, bool, error) {
+	return getClaim(p.tx, []byte(claimID))
+}
@@ -0,0 +1,285 @@
+package boltstore
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+var epoch = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newClaim returns a claim on resource pod-a for requirement CAT.T01.TR01 of TEST-CAT,
+// timestamped minutes after the epoch, with one compliant result per method.
+func newClaim(id string, minutes int, methods ...string) claims.ConformanceClaim {
+	claim := claims.ConformanceClaim{
+		ClaimID:     id,
+		Timestamp:   epoch.Add(time.Duration(minutes) * time.Minute),
+		ResourceRef: "pod-a",
+		CatalogID:   "TEST-CAT",
+		ControlID:   "CAT.T01",
+		Assessment:  layer4.Assessment{RequirementID: "CAT.T01.TR01"},
+	}
+	for _, name := range methods {
+		method := layer4.AssessmentMethod{Name: name, Run: true, Result: &layer4.AssessmentResult{}}
+		setStatus(&method.Result.Status, claims.StatusCompliant)
+		claim.Assessment.Methods = append(claim.Assessment.Methods, method)
+	}
+	return claim
+}
+
+func setStatus[T ~string](dst *T, status string) {
+	*dst = T(status)
+}
+
+func openStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "claims.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+	return store, path
+}
+
+func mustAdd(t *testing.T, store claims.Store, all ...claims.ConformanceClaim) {
+	t.Helper()
+	for _, claim := range all {
+		if err := store.Add(claim); err != nil {
+			t.Fatalf("add %s: %v", claim.ClaimID, err)
+		}
+	}
+}
+
+func ids(all []claims.ConformanceClaim) []string {
+	result := make([]string, 0, len(all))
+	for _, claim := range all {
+		result = append(result, claim.ClaimID)
+	}
+	return result
+}
+
+func sortedIDs(all []claims.ConformanceClaim) []string {
+	result := ids(all)
+	sort.Strings(result)
+	return result
+}
+
+func TestStorePersistsClaims(t *testing.T) {
+	store, path := openStore(t)
+	claim := newClaim("c1", 0, "OPA")
+	claim.Summary = "stored"
+	mustAdd(t, store, claim, newClaim("c2", 1, "Kyverno"))
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	got, ok, err := reopened.Get("c1")
+	if err != nil || !ok {
+		t.Fatalf("get c1: found %t, err %v", ok, err)
+	}
+	if got.Summary != "stored" || !got.Timestamp.Equal(claim.Timestamp) {
+		t.Errorf("get c1 = %+v, want %+v", got, claim)
+	}
+	all, err := reopened.GetClaims()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"c1", "c2"}; !slices.Equal(sortedIDs(all), want) {
+		t.Errorf("claims = %v, want %v", sortedIDs(all), want)
+	}
+	if _, ok, err := reopened.Get("missing"); ok || err != nil {
+		t.Errorf("get missing: found %t, err %v", ok, err)
+	}
+}
+
+func TestStoreDeleteAndLegalHold(t *testing.T) {
+	for _, storeName := range []string{"memory", "bolt"} {
+		t.Run(storeName, func(t *testing.T) {
+			var store claims.Store = claims.NewMemoryStore()
+			if storeName == "bolt" {
+				store, _ = openStore(t)
+			}
+			// c1 and c3 are superseded by c2 and c4; c2 and c4 are current.
+			mustAdd(t, store, newClaim("c1", 0, "OPA"), newClaim("c2", 1, "OPA"),
+				newClaim("c3", 0, "Kyverno"), newClaim("c4", 1, "Kyverno"))
+
+			if err := store.SetLegalHold("c3", true); err != nil {
+				t.Fatal(err)
+			}
+			held, _, err := store.Get("c3")
+			if err != nil || !held.LegalHold {
+				t.Fatalf("c3 legal hold = %t, err %v", held.LegalHold, err)
+			}
+			if err := store.SetLegalHold("missing", true); !errors.Is(err, claims.ErrNotFound) {
+				t.Errorf("hold on missing claim: %v, want ErrNotFound", err)
+			}
+
+			deleted, err := store.Delete("c1", "c2", "c3", "missing")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(deleted, []string{"c1"}) {
+				t.Errorf("deleted = %v, want [c1]", deleted)
+			}
+			all, err := store.GetClaims()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sortedIDs(all); !slices.Equal(got, []string{"c2", "c3", "c4"}) {
+				t.Errorf("claims = %v, want [c2 c3 c4]", got)
+			}
+			result, err := store.Query(claims.Query{Source: "OPA"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ids(result.Claims); !slices.Equal(got, []string{"c2"}) {
+				t.Errorf("OPA claims = %v, want [c2]", got)
+			}
+
+			// Releasing the hold lets the superseded claim be deleted.
+			if err := store.SetLegalHold("c3", false); err != nil {
+				t.Fatal(err)
+			}
+			if deleted, err := store.Delete("c3"); err != nil || !slices.Equal(deleted, []string{"c3"}) {
+				t.Errorf("delete c3 = %v, %v, want [c3]", deleted, err)
+			}
+		})
+	}
+}
+
+func TestOpenRejectsNewerSchema(t *testing.T) {
+	store, path := openStore(t)
+	if err := store.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(metaBucket).Put(schemaVersionKey, []byte("999"))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path); err == nil {
+		t.Fatal("expected an error opening a database with a newer schema")
+	}
+}
+
+func TestQuery(t *testing.T) {
+	other := newClaim("c3", 2, "Kyverno")
+	other.ResourceRef = "pod-b"
+	tenant := newClaim("c4", 3, "OPA")
+	tenant.Tenant = "team-a"
+	failing := newClaim("c5", 4, "OpenSCAP")
+	failing.ControlID = "CAT.T02"
+	failing.Assessment.RequirementID = "CAT.T02.TR01"
+	setStatus(&failing.Assessment.Methods[0].Result.Status, claims.StatusNotCompliant)
+	all := []claims.ConformanceClaim{newClaim("c1", 0, "OPA"), newClaim("c2", 1, "OPA"), other, tenant, failing}
+
+	tests := []struct {
+		name  string
+		query claims.Query
+		want  []string
+	}{
+		{"all ascending", claims.Query{}, []string{"c1", "c2", "c3", "c4", "c5"}},
+		{"descending", claims.Query{Order: claims.Descending}, []string{"c5", "c4", "c3", "c2", "c1"}},
+		{"resource", claims.Query{ResourceRef: "pod-b"}, []string{"c3"}},
+		{"requirement", claims.Query{RequirementID: "CAT.T02.TR01"}, []string{"c5"}},
+		{"control", claims.Query{ControlID: "CAT.T01"}, []string{"c1", "c2", "c3", "c4"}},
+		{"catalog and source", claims.Query{CatalogID: "TEST-CAT", Source: "OPA"}, []string{"c1", "c2", "c4"}},
+		{"status", claims.Query{Status: claims.StatusNotCompliant}, []string{"c5"}},
+		{"tenant", claims.Query{Tenant: "team-a"}, []string{"c4"}},
+		{"default tenant", claims.Query{Tenant: "default"}, []string{"c1", "c2", "c3", "c5"}},
+		{"current only", claims.Query{CurrentOnly: true, ResourceRef: "pod-a", Source: "OPA"}, []string{"c2", "c4"}},
+		{"time range", claims.Query{Start: epoch.Add(time.Minute), End: epoch.Add(3 * time.Minute)}, []string{"c2", "c3"}},
+		{"index and time range", claims.Query{ResourceRef: "pod-a", Start: epoch.Add(time.Minute)}, []string{"c2", "c4", "c5"}},
+	}
+	stores := map[string]claims.Store{"memory": claims.NewMemoryStore()}
+	stores["bolt"], _ = openStore(t)
+	for storeName, store := range stores {
+		mustAdd(t, store, all...)
+		for _, tt := range tests {
+			t.Run(storeName+"/"+tt.name, func(t *testing.T) {
+				result, err := store.Query(tt.query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := ids(result.Claims); !slices.Equal(got, tt.want) {
+					t.Errorf("claims = %v, want %v", got, tt.want)
+				}
+				if result.Total != len(tt.want) || result.NextCursor != "" {
+					t.Errorf("total = %d, next cursor = %q, want %d and no cursor", result.Total, result.NextCursor, len(tt.want))
+				}
+			})
+		}
+	}
+}
+
+func TestQueryPaging(t *testing.T) {
+	store, _ := openStore(t)
+	// c0 and c1 share a timestamp so the cursor must break ties by claim ID.
+	mustAdd(t, store, newClaim("c0", 0, "OPA"), newClaim("c1", 0, "Kyverno"), newClaim("c2", 1, "OPA"),
+		newClaim("c3", 2, "Kyverno"), newClaim("c4", 3, "OPA"))
+
+	for _, order := range []claims.SortOrder{claims.Ascending, claims.Descending} {
+		t.Run(string(order), func(t *testing.T) {
+			var got []string
+			query := claims.Query{Order: order, Limit: 2}
+			for pages := 0; ; pages++ {
+				if pages > 3 {
+					t.Fatal("paging did not terminate")
+				}
+				result, err := store.Query(query)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.Total != 5 {
+					t.Errorf("total = %d, want 5", result.Total)
+				}
+				got = append(got, ids(result.Claims)...)
+				if result.NextCursor == "" {
+					break
+				}
+				query.Cursor = result.NextCursor
+			}
+			want := []string{"c0", "c1", "c2", "c3", "c4"}
+			if order == claims.Descending {
+				slices.Reverse(want)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("pages = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestQueryRejectsInvalidQueries(t *testing.T) {
+	store, _ := openStore(t)
+	tests := []struct {
+		name  string
+		query claims.Query
+	}{
+		{"order", claims.Query{Order: "sideways"}},
+		{"limit", claims.Query{Limit: -1}},
+		{"range", claims.Query{Start: epoch, End: epoch}},
+		{"cursor", claims.Query{Cursor: "not a cursor!"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := store.Query(tt.query); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
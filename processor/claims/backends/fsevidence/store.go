@@ -0,0 +1,112 @@
+// Package fsevidence stores raw evidence on the local filesystem, addressed by the
+// sha256 digest of its content, so every claim's evidence reference can be retrieved.
+package fsevidence
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+var _ evidence.Store = (*Store)(nil)
+
+// Store is an evidence.Store in a directory. Documents are written to
+// <root>/<hex[0:2]>/<hex[2:4]>/<hex>, where hex is the sha256 digest the reference
+// names, so no directory grows too large.
+type Store struct {
+	root string
+}
+
+// Open returns a Store rooted at the directory, creating it if needed.
+func Open(root string) (*Store, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create evidence directory %s: %w", root, err)
+	}
+	return &Store{root: root}, nil
+}
+
+// Put writes the document if it is not already stored. The reference must be the
+// digest of the document. The document is written to a temporary file and renamed
+// into place, so readers never see a partial document.
+func (s *Store) Put(ref string, data []byte) error {
+	path, err := s.path(ref)
+	if err != nil {
+		return err
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return fmt.Errorf("evidence digest %s does not match reference %s", actual, ref)
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-"+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write evidence %s: %w", ref, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write evidence %s: %w", ref, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write evidence %s: %w", ref, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o440); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store evidence %s: %w", ref, err)
+	}
+	return syncDir(dir)
+}
+
+// Get reads the document stored under the reference and checks that it still matches
+// its digest.
+func (s *Store) Get(ref string) ([]byte, error) {
+	path, err := s.path(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("evidence %s: %w", ref, evidence.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return nil, fmt.Errorf("evidence %s is corrupt: content digest is %s", ref, actual)
+	}
+	return data, nil
+}
+
+// path returns the sharded location of the reference.
+func (s *Store) path(ref string) (string, error) {
+	digest, err := evidence.ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, digest[0:2], digest[2:4], digest), nil
+}
+
+// syncDir flushes the directory entry of a renamed file to disk.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
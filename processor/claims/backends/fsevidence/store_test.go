@@ -0,0 +1,149 @@
+package fsevidence
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+func openStore(t *testing.T) (*Store, string) {
+	t.Helper()
+	root := filepath.Join(t.TempDir(), "evidence")
+	store, err := Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, root
+}
+
+func TestPutGet(t *testing.T) {
+	store, root := openStore(t)
+	data := []byte(`{"id":"ev-1"}`)
+	ref := evidence.Digest(data)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+
+	digest := strings.TrimPrefix(ref, "sha256:")
+	info, err := os.Stat(filepath.Join(root, digest[0:2], digest[2:4], digest))
+	if err != nil {
+		t.Fatalf("document not stored at its sharded path: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o440 {
+		t.Errorf("document mode = %o, want 440", perm)
+	}
+
+	// References recorded before they named their algorithm address the same document.
+	for _, r := range []string{ref, digest} {
+		got, err := store.Get(r)
+		if err != nil {
+			t.Fatalf("get %s: %v", r, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("get %s = %s, want %s", r, got, data)
+		}
+	}
+}
+
+func TestPutIsImmutable(t *testing.T) {
+	store, root := openStore(t)
+	data := []byte(`{"id":"ev-1"}`)
+	ref := evidence.Digest(data)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	digest := strings.TrimPrefix(ref, "sha256:")
+	path := filepath.Join(root, digest[0:2], digest[2:4], digest)
+	before, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Put(ref, data); err != nil {
+		t.Fatalf("repeated put: %v", err)
+	}
+	after, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("repeated put replaced the stored document")
+	}
+
+	if err := store.Put(ref, []byte(`{"id":"ev-2"}`)); err == nil {
+		t.Error("expected an error storing a document under another document's reference")
+	}
+	got, err := store.Get(ref)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("get after mismatched put = %s, %v; want %s", got, err, data)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("shard holds %d entries, want only the stored document", len(entries))
+	}
+}
+
+func TestGetNotFound(t *testing.T) {
+	store, _ := openStore(t)
+	_, err := store.Get(evidence.Digest([]byte("missing")))
+	if !errors.Is(err, evidence.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetCorrupt(t *testing.T) {
+	store, root := openStore(t)
+	data := []byte(`{"id":"ev-1"}`)
+	ref := evidence.Digest(data)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	digest := strings.TrimPrefix(ref, "sha256:")
+	path := filepath.Join(root, digest[0:2], digest[2:4], digest)
+	if err := os.Chmod(path, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"id":"tampered"}`), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	_, err := store.Get(ref)
+	if err == nil || errors.Is(err, evidence.ErrNotFound) {
+		t.Errorf("error = %v, want a corruption error", err)
+	}
+}
+
+func TestInvalidRef(t *testing.T) {
+	store, root := openStore(t)
+	data := []byte(`{"id":"ev-1"}`)
+	digest := strings.TrimPrefix(evidence.Digest(data), "sha256:")
+	for _, ref := range []string{
+		"../../../etc/passwd",
+		"sha256:../../" + digest[6:],
+		"/" + digest[1:],
+		"sha256:" + strings.ToUpper(digest),
+		"sha512:" + digest,
+		"",
+	} {
+		if err := store.Put(ref, data); !errors.Is(err, evidence.ErrInvalidRef) {
+			t.Errorf("put %q: error = %v, want ErrInvalidRef", ref, err)
+		}
+		if _, err := store.Get(ref); !errors.Is(err, evidence.ErrInvalidRef) {
+			t.Errorf("get %q: error = %v, want ErrInvalidRef", ref, err)
+		}
+	}
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("invalid references wrote %d entries to the store", len(entries))
+	}
+}
@@ -0,0 +1,413 @@
+// Package ocievidence pushes raw evidence and claims to an OCI registry as artifacts,
+// so evidence can travel with the images it was collected for.
+package ocievidence
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/in-toto/go-witness/cryptoutil"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+const (
+	// EvidenceArtifactType is the artifact type of raw evidence documents.
+	EvidenceArtifactType = "application/vnd.shiny-journey.raw-evidence.v1+json"
+	// ClaimArtifactType is the artifact type of conformance claims.
+	ClaimArtifactType = claims.PayloadType
+	// DefaultTimeout bounds each store operation.
+	DefaultTimeout = 30 * time.Second
+
+	// annotationRef and annotationClaimID identify the evidence or claim an artifact holds.
+	annotationRef     = "dev.shiny-journey.evidence.ref"
+	annotationClaimID = "dev.shiny-journey.claim.id"
+)
+
+var _ evidence.Store = (*Store)(nil)
+
+// Store is an evidence.Store in a registry repository. Evidence is tagged
+// evidence-<hex digest> and claims claim-<claimId>. Each claim refers to its evidence as its
+// subject, so the claims made from evidence are listed by the referrers API.
+type Store struct {
+	repo    name.Repository
+	attach  bool
+	timeout time.Duration
+	nameOpt []name.Option
+	auth    remote.Option
+	extra   []remote.Option
+}
+
+type options struct {
+	attach   bool
+	insecure bool
+	timeout  time.Duration
+	keychain authn.Keychain
+	remote   []remote.Option
+}
+
+// Option configures a Store.
+type Option func(o *options)
+
+// WithAttach also pushes evidence to the repository of the resource image it was
+// collected for, with the image as its subject, when the resource names an image in a
+// registry and its digest includes sha256. Claims follow their evidence.
+func WithAttach(attach bool) Option {
+	return func(o *options) {
+		o.attach = attach
+	}
+}
+
+// WithInsecure allows registries served over plain HTTP, such as a local registry.
+func WithInsecure(insecure bool) Option {
+	return func(o *options) {
+		o.insecure = insecure
+	}
+}
+
+// WithKeychain sets where registry credentials are read from. The default is the
+// docker configuration of the user.
+func WithKeychain(keychain authn.Keychain) Option {
+	return func(o *options) {
+		o.keychain = keychain
+	}
+}
+
+// WithRemoteOptions passes options to every registry request, e.g. a transport.
+func WithRemoteOptions(opts ...remote.Option) Option {
+	return func(o *options) {
+		o.remote = append(o.remote, opts...)
+	}
+}
+
+// WithTimeout bounds each store operation. The default is DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// New returns a Store for the repository, e.g. registry.example.com/compliance/evidence.
+func New(repository string, opts ...Option) (*Store, error) {
+	o := options{
+		timeout:  DefaultTimeout,
+		keychain: authn.DefaultKeychain,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	nameOpts := []name.Option{name.StrictValidation}
+	if o.insecure {
+		nameOpts = append(nameOpts, name.Insecure)
+	}
+	repo, err := name.NewRepository(repository, nameOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid evidence repository %q: %w", repository, err)
+	}
+	return &Store{
+		repo:    repo,
+		attach:  o.attach,
+		timeout: o.timeout,
+		nameOpt: nameOpts,
+		auth:    remote.WithAuthFromKeychain(o.keychain),
+		extra:   o.remote,
+	}, nil
+}
+
+// Put pushes the document as an artifact if it is not already stored. The reference
+// must be the digest of the document.
+func (s *Store) Put(ref string, data []byte) error {
+	digest, err := evidence.ParseRef(ref)
+	if err != nil {
+		return err
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return fmt.Errorf("evidence digest %s does not match reference %s", actual, ref)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	tag := s.repo.Tag(evidenceTag(digest))
+	if _, err := remote.Head(tag, s.options(ctx)...); err == nil {
+		return nil
+	} else if !notFound(err) {
+		return fmt.Errorf("failed to check evidence %s in %s: %w", ref, s.repo, err)
+	}
+	artifact := newArtifact(EvidenceArtifactType, data, nil, evidenceAnnotations(ref, data))
+	if err := s.push(ctx, tag, artifact); err != nil {
+		return fmt.Errorf("failed to push evidence %s to %s: %w", ref, s.repo, err)
+	}
+	if s.attach {
+		if _, err := s.attachEvidence(ctx, ref, data); err != nil {
+			return fmt.Errorf("failed to attach evidence %s to its resource: %w", ref, err)
+		}
+	}
+	return nil
+}
+
+// Get pulls the document stored under the reference and checks that it still matches
+// its digest.
+func (s *Store) Get(ref string) ([]byte, error) {
+	digest, err := evidence.ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	// The document is the only layer of its artifact, so its blob digest is the reference.
+	layer, err := remote.Layer(s.repo.Digest("sha256:"+digest), s.options(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull evidence %s from %s: %w", ref, s.repo, err)
+	}
+	rc, err := layer.Compressed()
+	if notFound(err) {
+		return nil, fmt.Errorf("evidence %s: %w", ref, evidence.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull evidence %s from %s: %w", ref, s.repo, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pull evidence %s from %s: %w", ref, s.repo, err)
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return nil, fmt.Errorf("evidence %s is corrupt: content digest is %s", ref, actual)
+	}
+	return data, nil
+}
+
+// PutClaim pushes the claim as an artifact whose subject is its evidence, next to the
+// evidence in the repository and, when attaching, in the repository of the resource image.
+func (s *Store) PutClaim(claim claims.ConformanceClaim) error {
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	annotations := map[string]string{
+		annotationClaimID:                  claim.ClaimID,
+		annotationRef:                      claim.RawEvidenceRef,
+		"org.opencontainers.image.created": claim.Timestamp.UTC().Format(time.RFC3339),
+	}
+	digest, err := evidence.ParseRef(claim.RawEvidenceRef)
+	if err != nil {
+		return err
+	}
+	subject, err := remote.Head(s.repo.Tag(evidenceTag(digest)), s.options(ctx)...)
+	if err != nil {
+		return fmt.Errorf("failed to find evidence %s of claim %s in %s: %w", claim.RawEvidenceRef, claim.ClaimID, s.repo, err)
+	}
+	artifact := newArtifact(ClaimArtifactType, data, subject, annotations)
+	if err := s.push(ctx, s.repo.Tag("claim-"+claim.ClaimID), artifact); err != nil {
+		return fmt.Errorf("failed to push claim %s to %s: %w", claim.ClaimID, s.repo, err)
+	}
+	if !s.attach {
+		return nil
+	}
+	evidenceData, err := s.Get(claim.RawEvidenceRef)
+	if err != nil {
+		return err
+	}
+	attached, err := s.attachEvidence(ctx, claim.RawEvidenceRef, evidenceData)
+	if err != nil || attached == nil {
+		return err
+	}
+	artifact = newArtifact(ClaimArtifactType, data, &attached.descriptor, annotations)
+	if err := s.pushByDigest(ctx, attached.repo, artifact); err != nil {
+		return fmt.Errorf("failed to attach claim %s to %s: %w", claim.ClaimID, attached.repo, err)
+	}
+	return nil
+}
+
+// attachEvidence pushes the evidence to the repository of its resource image with the
+// image as its subject, and returns the pushed artifact. It returns nil when the
+// resource is not an image in a registry.
+func (s *Store) attachEvidence(ctx context.Context, ref string, data []byte) (*attachedArtifact, error) {
+	var rawEv evidence.RawEvidence
+	if err := json.Unmarshal(data, &rawEv); err != nil {
+		return nil, nil
+	}
+	image, ok := s.resourceImage(rawEv.Resource)
+	if !ok {
+		return nil, nil
+	}
+	subject, err := remote.Head(image, s.options(ctx)...)
+	if notFound(err) {
+		log.Printf("Not attaching evidence %s: image %s is not in its registry", ref, image)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	artifact := newArtifact(EvidenceArtifactType, data, subject, evidenceAnnotations(ref, data))
+	if err := s.pushByDigest(ctx, image.Context(), artifact); err != nil {
+		return nil, err
+	}
+	descriptor, err := artifact.descriptor()
+	if err != nil {
+		return nil, err
+	}
+	return &attachedArtifact{repo: image.Context(), descriptor: descriptor}, nil
+}
+
+// resourceImage returns the image the resource names, when the name includes its
+// registry and the digest set includes sha256.
+func (s *Store) resourceImage(resource evidence.Resource) (name.Digest, bool) {
+	hex, ok := resource.Digest[cryptoutil.DigestValue{Hash: crypto.SHA256}]
+	if !ok || resource.Name == "" {
+		return name.Digest{}, false
+	}
+	repository := resource.Name
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	repo, err := name.NewRepository(repository, s.nameOpt...)
+	if err != nil {
+		return name.Digest{}, false
+	}
+	return repo.Digest("sha256:" + hex), true
+}
+
+func (s *Store) push(ctx context.Context, ref name.Reference, a artifact) error {
+	opts := s.options(ctx)
+	for _, blob := range a.blobs {
+		if err := remote.WriteLayer(ref.Context(), blob, opts...); err != nil {
+			return err
+		}
+	}
+	return remote.Put(ref, a, opts...)
+}
+
+// pushByDigest pushes an untagged artifact, which is only found through its subject.
+func (s *Store) pushByDigest(ctx context.Context, repo name.Repository, a artifact) error {
+	descriptor, err := a.descriptor()
+	if err != nil {
+		return err
+	}
+	return s.push(ctx, repo.Digest(descriptor.Digest.String()), a)
+}
+
+func (s *Store) options(ctx context.Context) []remote.Option {
+	return append([]remote.Option{remote.WithContext(ctx), s.auth}, s.extra...)
+}
+
+// attachedArtifact is an evidence artifact pushed to the repository of a resource image.
+type attachedArtifact struct {
+	repo       name.Repository
+	descriptor v1.Descriptor
+}
+
+// evidenceTag returns the tag of evidence with the hex-encoded digest. Tags cannot
+// contain the colon of a reference.
+func evidenceTag(digest string) string {
+	return "evidence-" + digest
+}
+
+func evidenceAnnotations(ref string, data []byte) map[string]string {
+	annotations := map[string]string{annotationRef: ref}
+	var rawEv evidence.RawEvidence
+	if err := json.Unmarshal(data, &rawEv); err == nil && !rawEv.Timestamp.IsZero() {
+		annotations["org.opencontainers.image.created"] = rawEv.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return annotations
+}
+
+func notFound(err error) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound
+}
+
+// manifest is an OCI image manifest with an artifact type, which v1.Manifest lacks.
+type manifest struct {
+	SchemaVersion int64             `json:"schemaVersion"`
+	MediaType     types.MediaType   `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        v1.Descriptor     `json:"config"`
+	Layers        []v1.Descriptor   `json:"layers"`
+	Subject       *v1.Descriptor    `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// artifact is a manifest with a single JSON layer and the blobs it references.
+type artifact struct {
+	manifest manifest
+	blobs    []v1.Layer
+}
+
+func newArtifact(artifactType string, data []byte, subject *v1.Descriptor, annotations map[string]string) artifact {
+	// The config carries the artifact type as well, since registries without the
+	// referrers API, and many clients, report the config media type as the artifact type.
+	config := static.NewLayer([]byte("{}"), types.MediaType(artifactType))
+	layer := static.NewLayer(data, types.MediaType("application/json"))
+	m := manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  artifactType,
+		Config:        describe(config),
+		Layers:        []v1.Descriptor{describe(layer)},
+		Annotations:   annotations,
+	}
+	if subject != nil {
+		m.Subject = &v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest}
+	}
+	return artifact{manifest: m, blobs: []v1.Layer{config, layer}}
+}
+
+// RawManifest implements remote.Taggable.
+func (a artifact) RawManifest() ([]byte, error) {
+	return json.Marshal(a.manifest)
+}
+
+// MediaType is the media type the manifest is pushed with.
+func (a artifact) MediaType() (types.MediaType, error) {
+	return a.manifest.MediaType, nil
+}
+
+func (a artifact) descriptor() (v1.Descriptor, error) {
+	raw, err := a.RawManifest()
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	digest, size, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return v1.Descriptor{}, err
+	}
+	return v1.Descriptor{
+		MediaType:    a.manifest.MediaType,
+		ArtifactType: a.manifest.ArtifactType,
+		Size:         size,
+		Digest:       digest,
+	}, nil
+}
+
+// describe returns the descriptor of a static layer, which cannot fail to compute.
+func describe(layer v1.Layer) v1.Descriptor {
+	digest, _ := layer.Digest()
+	size, _ := layer.Size()
+	mediaType, _ := layer.MediaType()
+	return v1.Descriptor{MediaType: mediaType, Size: size, Digest: digest}
+}
@@ -0,0 +1,216 @@
+package ocievidence
+
+import (
+	"crypto"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/in-toto/go-witness/cryptoutil"
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// newRegistry starts an in-process registry with the referrers API and returns its host.
+func newRegistry(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(registry.New(
+		registry.WithReferrersSupport(true),
+		registry.Logger(log.New(io.Discard, "", 0)),
+	))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func newStore(t *testing.T, repository string, opts ...Option) *Store {
+	t.Helper()
+	opts = append([]Option{WithInsecure(true), WithKeychain(authn.NewMultiKeychain()), WithTimeout(5 * time.Second)}, opts...)
+	store, err := New(repository, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// rawEvidence returns a raw evidence document for the resource and its reference.
+func rawEvidence(t *testing.T, resource evidence.Resource) ([]byte, string) {
+	t.Helper()
+	data, err := json.Marshal(evidence.RawEvidence{
+		Metadata: evidence.Metadata{
+			ID:        "ev-1",
+			Timestamp: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			Source:    "OPA",
+			PolicyID:  "p1",
+			Decision:  "allow",
+		},
+		Resource: resource,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, evidence.Digest(data)
+}
+
+func claimFor(ref string) claims.ConformanceClaim {
+	return claims.ConformanceClaim{
+		ClaimID:        "claim-1",
+		Timestamp:      time.Date(2025, 6, 1, 12, 0, 1, 0, time.UTC),
+		ResourceRef:    "pod-a",
+		RawEvidenceRef: ref,
+		CatalogID:      "TEST-CAT",
+		ControlID:      "CAT.T01",
+		Assessment:     layer4.Assessment{RequirementID: "CAT.T01.TR01"},
+	}
+}
+
+// referrer returns the only manifest referring to the digest and checks its artifact type.
+func referrer(t *testing.T, digest name.Digest, artifactType string) v1.Descriptor {
+	t.Helper()
+	index, err := remote.Referrers(digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Manifests) != 1 || manifest.Manifests[0].ArtifactType != artifactType {
+		t.Fatalf("referrers of %s = %+v, want one %s", digest, manifest.Manifests, artifactType)
+	}
+	return manifest.Manifests[0]
+}
+
+func TestPutGet(t *testing.T) {
+	store := newStore(t, newRegistry(t)+"/compliance/evidence")
+	data, ref := rawEvidence(t, evidence.Resource{Name: "pod-a"})
+
+	for i := 0; i < 2; i++ {
+		if err := store.Put(ref, data); err != nil {
+			t.Fatalf("put %d: %v", i, err)
+		}
+	}
+	got, err := store.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(data) {
+		t.Errorf("get = %s, want %s", got, data)
+	}
+
+	if err := store.Put(evidence.Digest([]byte("other")), data); err == nil || !strings.Contains(err.Error(), "does not match reference") {
+		t.Errorf("put under the wrong reference: %v", err)
+	}
+	if _, err := store.Get(evidence.Digest([]byte("missing"))); !errors.Is(err, evidence.ErrNotFound) {
+		t.Errorf("get missing evidence: %v, want ErrNotFound", err)
+	}
+}
+
+func TestPutClaimRefersToEvidence(t *testing.T) {
+	repository := newRegistry(t) + "/compliance/evidence"
+	store := newStore(t, repository)
+	data, ref := rawEvidence(t, evidence.Resource{Name: "pod-a"})
+
+	if err := store.PutClaim(claimFor(ref)); err == nil {
+		t.Fatal("expected an error pushing a claim before its evidence")
+	}
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutClaim(claimFor(ref)); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := name.NewRepository(repository, name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evidenceManifest, err := remote.Head(repo.Tag(evidenceTag(strings.TrimPrefix(ref, "sha256:"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	referrer(t, repo.Digest(evidenceManifest.Digest.String()), ClaimArtifactType)
+
+	claimImage, err := remote.Image(repo.Tag("claim-claim-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := claimImage.Layers()
+	if err != nil || len(layers) != 1 {
+		t.Fatalf("claim artifact layers = %d, err %v", len(layers), err)
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	var claim claims.ConformanceClaim
+	if err := json.NewDecoder(rc).Decode(&claim); err != nil {
+		t.Fatal(err)
+	}
+	if claim.ClaimID != "claim-1" || claim.RawEvidenceRef != ref {
+		t.Errorf("claim artifact = %+v", claim)
+	}
+}
+
+func TestAttachToResourceImage(t *testing.T) {
+	host := newRegistry(t)
+	image, err := random.Image(256, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageRef, err := name.ParseReference(host+"/apps/web:1.0", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(imageRef, image); err != nil {
+		t.Fatal(err)
+	}
+	imageDigest, err := image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := newStore(t, host+"/compliance/evidence", WithAttach(true))
+	data, ref := rawEvidence(t, evidence.Resource{
+		Name:   host + "/apps/web:1.0",
+		Digest: cryptoutil.DigestSet{{Hash: crypto.SHA256}: imageDigest.Hex},
+	})
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutClaim(claimFor(ref)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The image refers to its evidence, which refers to the claim.
+	attached := referrer(t, imageRef.Context().Digest(imageDigest.String()), EvidenceArtifactType)
+	referrer(t, imageRef.Context().Digest(attached.Digest.String()), ClaimArtifactType)
+}
+
+func TestAttachSkipsImagesOutsideRegistry(t *testing.T) {
+	host := newRegistry(t)
+	store := newStore(t, host+"/compliance/evidence", WithAttach(true))
+	data, ref := rawEvidence(t, evidence.Resource{
+		Name:   host + "/apps/missing:1.0",
+		Digest: cryptoutil.DigestSet{{Hash: crypto.SHA256}: strings.Repeat("a", 64)},
+	})
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.PutClaim(claimFor(ref)); err != nil {
+		t.Fatal(err)
+	}
+}
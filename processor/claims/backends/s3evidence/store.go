@@ -0,0 +1,267 @@
+// Package s3evidence stores raw evidence in S3-compatible object storage, such as
+// MinIO, keyed by the sha256 digest of its content.
+package s3evidence
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+const (
+	// DefaultMaxRetries is how often a failed request is retried with backoff.
+	DefaultMaxRetries = 5
+	// DefaultTimeout bounds each store operation, including its retries.
+	DefaultTimeout = 30 * time.Second
+)
+
+var _ evidence.Store = (*Store)(nil)
+
+// Store is an evidence.Store in a bucket. Each document is an object named by the
+// digest of its reference, with the source, resource, and timestamp of the evidence as object metadata.
+type Store struct {
+	client    *s3.S3
+	bucket    string
+	prefix    string
+	lockMode  string
+	retention time.Duration
+	timeout   time.Duration
+}
+
+type options struct {
+	endpoint   string
+	region     string
+	prefix     string
+	lockMode   string
+	retention  time.Duration
+	maxRetries int
+	timeout    time.Duration
+}
+
+// Option configures a Store.
+type Option func(o *options)
+
+// WithEndpoint sets the URL of an S3-compatible service, such as a MinIO server.
+// Objects are then addressed by path rather than by virtual host.
+func WithEndpoint(endpoint string) Option {
+	return func(o *options) {
+		o.endpoint = endpoint
+	}
+}
+
+// WithRegion sets the region of the bucket. The default is read from the environment.
+func WithRegion(region string) Option {
+	return func(o *options) {
+		o.region = region
+	}
+}
+
+// WithPrefix sets a prefix for object keys, e.g. "evidence/".
+func WithPrefix(prefix string) Option {
+	return func(o *options) {
+		o.prefix = prefix
+	}
+}
+
+// WithObjectLock retains each object for the duration under the object lock mode,
+// GOVERNANCE or COMPLIANCE. The bucket must be created with object lock enabled.
+func WithObjectLock(mode string, retention time.Duration) Option {
+	return func(o *options) {
+		o.lockMode = mode
+		o.retention = retention
+	}
+}
+
+// WithMaxRetries sets how often a failed request is retried. The default is DefaultMaxRetries.
+func WithMaxRetries(retries int) Option {
+	return func(o *options) {
+		o.maxRetries = retries
+	}
+}
+
+// WithTimeout bounds each store operation. The default is DefaultTimeout.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// New returns a Store for the bucket. Credentials are read from the environment,
+// shared configuration, or instance role, as with the AWS CLI.
+func New(bucket string, opts ...Option) (*Store, error) {
+	if bucket == "" {
+		return nil, errors.New("evidence bucket is required")
+	}
+	o := options{
+		maxRetries: DefaultMaxRetries,
+		timeout:    DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch o.lockMode {
+	case "", s3.ObjectLockModeGovernance, s3.ObjectLockModeCompliance:
+	default:
+		return nil, fmt.Errorf("invalid object lock mode %q, expected %s or %s", o.lockMode, s3.ObjectLockModeGovernance, s3.ObjectLockModeCompliance)
+	}
+	if o.lockMode != "" && o.retention <= 0 {
+		return nil, errors.New("object lock requires a positive retention period")
+	}
+
+	config := aws.NewConfig().WithMaxRetries(o.maxRetries)
+	if o.region != "" {
+		config = config.WithRegion(o.region)
+	}
+	if o.endpoint != "" {
+		config = config.WithEndpoint(o.endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure evidence bucket %s: %w", bucket, err)
+	}
+	return &Store{
+		client:    s3.New(sess),
+		bucket:    bucket,
+		prefix:    o.prefix,
+		lockMode:  o.lockMode,
+		retention: o.retention,
+		timeout:   o.timeout,
+	}, nil
+}
+
+// Put uploads the document if no object exists under the reference. The reference
+// must be the digest of the document.
+func (s *Store) Put(ref string, data []byte) error {
+	key, err := s.key(ref)
+	if err != nil {
+		return err
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return fmt.Errorf("evidence digest %s does not match reference %s", actual, ref)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	_, err = s.client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return nil
+	}
+	if !notFound(err) {
+		return fmt.Errorf("failed to check evidence %s in bucket %s: %w", ref, s.bucket, err)
+	}
+
+	// Object lock requires a Content-MD5 header on uploads.
+	sum := md5.Sum(data)
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+		ContentMD5:  aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+		Metadata:    aws.StringMap(objectMetadata(data)),
+	}
+	if s.lockMode != "" {
+		input.ObjectLockMode = aws.String(s.lockMode)
+		input.ObjectLockRetainUntilDate = aws.Time(time.Now().Add(s.retention))
+	}
+	if _, err := s.client.PutObjectWithContext(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload evidence %s to bucket %s: %w", ref, s.bucket, err)
+	}
+	return nil
+}
+
+// Get downloads the document stored under the reference and checks that it still
+// matches its digest.
+func (s *Store) Get(ref string) ([]byte, error) {
+	key, err := s.key(ref)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	out, err := s.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if notFound(err) {
+		return nil, fmt.Errorf("evidence %s: %w", ref, evidence.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to download evidence %s from bucket %s: %w", ref, s.bucket, err)
+	}
+	defer out.Body.Close()
+	data, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download evidence %s from bucket %s: %w", ref, s.bucket, err)
+	}
+	if actual := evidence.Digest(data); !evidence.SameRef(actual, ref) {
+		return nil, fmt.Errorf("evidence %s is corrupt: content digest is %s", ref, actual)
+	}
+	return data, nil
+}
+
+// key returns the object key of the reference: the prefix and the hex-encoded digest.
+func (s *Store) key(ref string) (string, error) {
+	digest, err := evidence.ParseRef(ref)
+	if err != nil {
+		return "", err
+	}
+	return s.prefix + digest, nil
+}
+
+// notFound reports whether the error is a missing object. HeadObject responses have no
+// body, so a missing object is only identified by its status code.
+func notFound(err error) bool {
+	var reqErr awserr.RequestFailure
+	if errors.As(err, &reqErr) && reqErr.StatusCode() == http.StatusNotFound {
+		return true
+	}
+	var awsErr awserr.Error
+	return errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey
+}
+
+// objectMetadata describes the evidence in the object metadata so objects can be
+// found by source, resource, or time without reading them. Documents that are not
+// raw evidence, such as signed attestations, have no metadata.
+func objectMetadata(data []byte) map[string]string {
+	var rawEv evidence.RawEvidence
+	if err := json.Unmarshal(data, &rawEv); err != nil {
+		return nil
+	}
+	metadata := make(map[string]string)
+	set := func(key, value string) {
+		if value != "" {
+			metadata[key] = value
+		}
+	}
+	set("evidence-id", rawEv.ID)
+	set("source", rawEv.Source)
+	set("policy-id", rawEv.PolicyID)
+	set("resource", rawEv.Resource.Name)
+	set("tenant", rawEv.Tenant)
+	if !rawEv.Timestamp.IsZero() {
+		metadata["timestamp"] = rawEv.Timestamp.UTC().Format(time.RFC3339)
+	}
+	return metadata
+}
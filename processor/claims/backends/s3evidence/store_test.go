@@ -0,0 +1,270 @@
+package s3evidence
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// fakeS3 is a path-style S3 endpoint holding objects in memory.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	// puts records the headers of each upload by object path.
+	puts map[string]http.Header
+	// failures is the number of requests to fail with a server error before serving.
+	failures int
+	requests int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if f.failures > 0 {
+		f.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	data, ok := f.objects[r.URL.Path]
+	switch r.Method {
+	case http.MethodHead:
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case http.MethodGet:
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Write(data)
+	case http.MethodPut:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+		f.puts[r.URL.Path] = r.Header.Clone()
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// newStore returns a Store for the bucket "evidence" on a fake S3 endpoint.
+func newStore(t *testing.T, opts ...Option) (*Store, *fakeS3) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+
+	fake := &fakeS3{objects: make(map[string][]byte), puts: make(map[string]http.Header)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	opts = append([]Option{WithEndpoint(server.URL), WithRegion("us-east-1"), WithMaxRetries(0), WithTimeout(5 * time.Second)}, opts...)
+	store, err := New("evidence", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, fake
+}
+
+// rawEvidence returns a raw evidence document and its reference.
+func rawEvidence(t *testing.T) ([]byte, string) {
+	t.Helper()
+	data, err := json.Marshal(evidence.RawEvidence{
+		Metadata: evidence.Metadata{
+			ID:        "ev-1",
+			Timestamp: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			Source:    "OPA",
+			PolicyID:  "require-labels",
+			Tenant:    "team-a",
+		},
+		Resource: evidence.Resource{Name: "pod-a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, evidence.Digest(data)
+}
+
+func TestPutGet(t *testing.T) {
+	store, fake := newStore(t, WithPrefix("raw/"))
+	data, ref := rawEvidence(t)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/evidence/raw/" + strings.TrimPrefix(ref, "sha256:")
+	header, ok := fake.puts[path]
+	if !ok {
+		t.Fatalf("no object uploaded to %s, got %v", path, fake.puts)
+	}
+	sum := md5.Sum(data)
+	want := map[string]string{
+		"Content-Type":           "application/json",
+		"Content-Md5":            base64.StdEncoding.EncodeToString(sum[:]),
+		"X-Amz-Meta-Evidence-Id": "ev-1",
+		"X-Amz-Meta-Source":      "OPA",
+		"X-Amz-Meta-Policy-Id":   "require-labels",
+		"X-Amz-Meta-Resource":    "pod-a",
+		"X-Amz-Meta-Tenant":      "team-a",
+		"X-Amz-Meta-Timestamp":   "2025-06-01T12:00:00Z",
+	}
+	for key, value := range want {
+		if got := header.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if mode := header.Get("X-Amz-Object-Lock-Mode"); mode != "" {
+		t.Errorf("object lock mode %q set without object lock", mode)
+	}
+
+	got, err := store.Get(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("get = %s, want %s", got, data)
+	}
+}
+
+func TestPutSkipsStoredObjects(t *testing.T) {
+	store, fake := newStore(t)
+	data, ref := rawEvidence(t)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	path := "/evidence/" + strings.TrimPrefix(ref, "sha256:")
+	fake.objects[path] = []byte("first upload")
+	delete(fake.puts, path)
+
+	if err := store.Put(ref, data); err != nil {
+		t.Fatalf("repeated put: %v", err)
+	}
+	if _, ok := fake.puts[path]; ok {
+		t.Error("repeated put uploaded the object again")
+	}
+	if string(fake.objects[path]) != "first upload" {
+		t.Errorf("stored object was replaced with %s", fake.objects[path])
+	}
+
+	if err := store.Put(ref, []byte(`{"id":"other"}`)); err == nil {
+		t.Error("expected an error storing a document under another document's reference")
+	}
+}
+
+func TestPutObjectLock(t *testing.T) {
+	store, fake := newStore(t, WithObjectLock("COMPLIANCE", 24*time.Hour))
+	data, ref := rawEvidence(t)
+	start := time.Now()
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	header := fake.puts["/evidence/"+strings.TrimPrefix(ref, "sha256:")]
+	if mode := header.Get("X-Amz-Object-Lock-Mode"); mode != "COMPLIANCE" {
+		t.Errorf("object lock mode = %q, want COMPLIANCE", mode)
+	}
+	until, err := time.Parse(time.RFC3339, header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	if err != nil {
+		t.Fatalf("retain until date: %v", err)
+	}
+	if until.Before(start.Add(24*time.Hour).Truncate(time.Second)) || until.After(time.Now().Add(24*time.Hour)) {
+		t.Errorf("retained until %s, want 24h after the upload", until)
+	}
+}
+
+func TestPutRetries(t *testing.T) {
+	store, fake := newStore(t, WithMaxRetries(2))
+	fake.failures = 2
+	data, ref := rawEvidence(t)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatalf("put with retries: %v", err)
+	}
+	if len(fake.puts) != 1 {
+		t.Errorf("uploaded %d objects, want 1", len(fake.puts))
+	}
+
+	store, fake = newStore(t)
+	fake.failures = 1
+	if err := store.Put(ref, data); err == nil {
+		t.Error("expected an error when retries are disabled")
+	}
+}
+
+func TestGetNotFound(t *testing.T) {
+	store, _ := newStore(t)
+	_, ref := rawEvidence(t)
+	if _, err := store.Get(ref); !errors.Is(err, evidence.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetCorrupt(t *testing.T) {
+	store, fake := newStore(t)
+	data, ref := rawEvidence(t)
+	if err := store.Put(ref, data); err != nil {
+		t.Fatal(err)
+	}
+	fake.objects["/evidence/"+strings.TrimPrefix(ref, "sha256:")] = []byte(`{"id":"tampered"}`)
+	_, err := store.Get(ref)
+	if err == nil || errors.Is(err, evidence.ErrNotFound) {
+		t.Errorf("error = %v, want a corruption error", err)
+	}
+}
+
+func TestInvalidRef(t *testing.T) {
+	store, fake := newStore(t)
+	data, ref := rawEvidence(t)
+	digest := strings.TrimPrefix(ref, "sha256:")
+	for _, invalid := range []string{"../" + digest[3:], "sha512:" + digest, "sha256:" + strings.ToUpper(digest)} {
+		if err := store.Put(invalid, data); !errors.Is(err, evidence.ErrInvalidRef) {
+			t.Errorf("put %q: error = %v, want ErrInvalidRef", invalid, err)
+		}
+		if _, err := store.Get(invalid); !errors.Is(err, evidence.ErrInvalidRef) {
+			t.Errorf("get %q: error = %v, want ErrInvalidRef", invalid, err)
+		}
+	}
+	if fake.requests != 0 {
+		t.Errorf("invalid references made %d requests", fake.requests)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		bucket  string
+		opts    []Option
+		wantErr bool
+	}{
+		{"bucket", "evidence", nil, false},
+		{"missing bucket", "", nil, true},
+		{"governance lock", "evidence", []Option{WithObjectLock("GOVERNANCE", time.Hour)}, false},
+		{"unknown lock mode", "evidence", []Option{WithObjectLock("LEGAL", time.Hour)}, true},
+		{"lock without retention", "evidence", []Option{WithObjectLock("COMPLIANCE", 0)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.bucket, append([]Option{WithRegion("us-east-1")}, tt.opts...)...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,14 +1,15 @@
 package claims
 
 import (
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/in-toto/go-witness/dsse"
 	"github.com/revanite-io/sci/layer4"
 
 	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
 )
 
 // ConformanceClaim represents a higher-level, mapped conformance assertion.
@@ -21,78 +22,122 @@ type ConformanceClaim struct {
 	Assessment     layer4.Assessment `json:"assessment"`
 	CatalogID      string            `json:"catalogId"`
 	ControlID      string            `json:"controlId"`
+	// Tenant is the cluster or team that reported the evidence. Claims from different
+	// tenants never supersede each other.
+	Tenant string `json:"tenant,omitempty"`
+	// ConfigRevision is the revision of the mapping ruleset that produced the claim.
+	ConfigRevision string `json:"configRevision"`
+	// DerivedFrom is the ID of the native claim this claim was derived from through a crosswalk.
+	DerivedFrom string `json:"derivedFrom,omitempty"`
+	// MappingStrength rates how closely the derived requirement corresponds to the native one.
+	MappingStrength int `json:"mappingStrength,omitempty"`
+	// SupersededBy is the ID of a newer claim for the same catalog, requirement, resource,
+	// and method. Claims that are not superseded make up the current posture.
+	SupersededBy string `json:"supersededBy,omitempty"`
+	// LegalHold exempts the claim from retention policies.
+	LegalHold bool `json:"legalHold,omitempty"`
+	// Manual is the attestation a manual claim was made from.
+	Manual *ManualAttestation `json:"manual,omitempty"`
+	// Provenance records the inputs the claim was produced from.
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// Signature is a DSSE envelope over the canonical JSON of the claim, see Sign.
+	Signature *dsse.Envelope `json:"signature,omitempty"`
 }
 
-func (c *ConformanceClaim) MarshalJSON() ([]byte, error) {
-	outputMap := make(map[string]interface{})
-	outputMap["clamId"] = c.ClaimID
-	outputMap["timestamp"] = c.Timestamp
-	outputMap["resourceRef"] = c.ResourceRef
-	outputMap["summary"] = c.Summary
-	outputMap["catalogId"] = c.CatalogID
-	outputMap["controlId"] = c.ControlID
-	assessment := make(map[string]interface{})
-	assessment["requirement_id"] = c.Assessment.RequirementID
-
-	var methods []map[string]interface{}
-	for _, method := range c.Assessment.Methods {
-		methodMap := make(map[string]interface{})
-		methodMap["name"] = method.Name
-		methodMap["description"] = method.Description
-		methodMap["run"] = method.Run
-		if method.Result != nil {
-			methodMap["result"] = map[string]interface{}{
-				"status": method.Result.Status,
-			}
-		}
-		methods = append(methods, methodMap)
+// NewFromEvidence creates a claim for the requirement the ruleset maps the evidence to.
+func NewFromEvidence(rawEnv evidence.RawEvidence, evidenceRef string, ruleset *mapping.Ruleset) (*ConformanceClaim, error) {
+	resolution, err := ruleset.Explain(rawEnv)
+	if err != nil {
+		return nil, err
 	}
-	assessment["methods"] = methods
-	outputMap["assessment"] = assessment
-	return json.Marshal(outputMap)
-}
-
-func NewFromEvidence(rawEnv evidence.RawEvidence, evidenceRef string, plan layer4.Layer4) *ConformanceClaim {
+	target := resolution.Target
 	claimID := uuid.New().String()
 	claim := ConformanceClaim{
 		ClaimID:        claimID,
 		Timestamp:      time.Now(),
 		ResourceRef:    rawEnv.Resource.Name,
 		RawEvidenceRef: evidenceRef,
+		Tenant:         evidence.TenantName(rawEnv.Tenant),
+		ConfigRevision: ruleset.Revision,
+		Provenance:     newProvenance(resolution),
+	}
+	if !rawEnv.Timestamp.IsZero() {
+		timestamp := rawEnv.Timestamp
+		claim.Provenance.EvidenceTimestamp = &timestamp
+	}
+	claim.CatalogID = target.CatalogID
+	claim.ControlID = target.ControlID
+	if err := claim.PopulateAssessment(rawEnv, target.RequirementID); err != nil {
+		return nil, err
+	}
+	return &claim, nil
+}
+
+// TenantID returns the tenant the claim belongs to. Claims made before tenants were
+// recorded belong to the default tenant.
+func (c ConformanceClaim) TenantID() string {
+	return evidence.TenantName(c.Tenant)
+}
+
+// Derive creates a claim against a requirement in another framework from a native claim.
+// The assessment methods and evidence reference are shared with the native claim.
+func Derive(native ConformanceClaim, derivation mapping.Derivation) *ConformanceClaim {
+	claim := native
+	claim.ClaimID = uuid.New().String()
+	claim.CatalogID = derivation.Target.CatalogID
+	claim.ControlID = derivation.Target.ControlID
+	claim.DerivedFrom = native.ClaimID
+	claim.SupersededBy = ""
+	claim.Signature = nil
+	if native.Provenance != nil {
+		provenance := *native.Provenance
+		provenance.Mapping = derivation.Mapping
+		provenance.MappingRevision = derivation.MappingRevision
+		provenance.CatalogVersion = derivation.CatalogVersion
+		claim.Provenance = &provenance
+	}
+	claim.MappingStrength = derivation.Strength
+	claim.Summary = fmt.Sprintf("%s Derived from claim %s (%s %s) with mapping strength %d.",
+		native.Summary, native.ClaimID, native.CatalogID, native.Assessment.RequirementID, derivation.Strength)
+	claim.Assessment = layer4.Assessment{
+		RequirementID: derivation.Target.RequirementID,
+		Methods:       append([]layer4.AssessmentMethod(nil), native.Assessment.Methods...),
 	}
-	claim.CatalogID = plan.CatalogID
-	claim.ControlID = simulateMapping("placeholder")
-	claim.PopulateAssessment(rawEnv)
 	return &claim
 }
 
 // PopulateAssessment simulates evaluations of evidence against policies.
-func (c *ConformanceClaim) PopulateAssessment(rawEv evidence.RawEvidence) {
-	summary := fmt.Sprintf("Resource '%s' from %s is %s against policy '%s'.",
+// It fails when the evidence source is not supported.
+func (c *ConformanceClaim) PopulateAssessment(rawEv evidence.RawEvidence, requirementID string) error {
+	assessment, err := simulatedCheck(rawEv, requirementID)
+	if err != nil {
+		return err
+	}
+	c.Summary = fmt.Sprintf("Resource '%s' from %s is %s against policy '%s'.",
 		rawEv.Resource, rawEv.Source, rawEv.Decision, rawEv.PolicyID)
-	c.Summary = summary
-	c.Assessment = simulatedCheck(rawEv)
+	c.Assessment = assessment
+	return nil
 }
 
-func simulateMapping(_ string) string {
-	return "CTRL-1"
-}
-
-// simulateMapping calls a mapping type depending on evidence type.
+// simulatedCheck calls a mapping type depending on evidence type.
 // In a real scenario, this would be delegated to a provider.
-func simulatedCheck(rawEv evidence.RawEvidence) layer4.Assessment {
-	// This would likely come from an imported mapping of methods to requirement ids. Probably in the form of a layer4.Evalution in
-	// a pre-run state.
+func simulatedCheck(rawEv evidence.RawEvidence, requirementID string) (layer4.Assessment, error) {
 	assessment := layer4.Assessment{
-		RequirementID: "CTRL-1.1",
+		RequirementID: requirementID,
 	}
 	methodMapper, ok := sourceToMethod[rawEv.Source]
 	if !ok {
-		panic(fmt.Sprintf("Wrong source %s", rawEv.Source))
+		return layer4.Assessment{}, fmt.Errorf("evidence %s: unsupported evidence source %q", rawEv.ID, rawEv.Source)
 	}
 	method := methodMapper(rawEv)
 	assessment.Methods = append(assessment.Methods, method)
-	return assessment
+	return assessment, nil
+}
+
+// SupportsSource reports whether evidence from the source can be mapped to an assessment method.
+func SupportsSource(source string) bool {
+	_, ok := sourceToMethod[source]
+	return ok
 }
 
 type methodMapperFunc func(rawEv evidence.RawEvidence) layer4.AssessmentMethod
@@ -104,12 +149,20 @@ var sourceToMethod = map[string]methodMapperFunc{
 			Run:    true,
 			Result: &layer4.AssessmentResult{},
 		}
-		if rawEv.Decision == "deny" {
-			method.Result.Status = "NOT_COMPLIANT"
+		switch rawEv.Decision {
+		case "deny":
+			method.Result.Status = StatusNotCompliant
 			method.Description = fmt.Sprintf("OPA denied access to resource '%s' due to policy '%s' violation. %s", rawEv.Resource, rawEv.PolicyID, string(rawEv.Details))
-		} else if rawEv.Decision == "allow" {
-			method.Result.Status = "COMPLIANT"
+		case "allow":
+			method.Result.Status = StatusCompliant
 			method.Description = fmt.Sprintf("OPA allowed access to resource '%s' adhering to policy '%s'.", rawEv.Resource, rawEv.PolicyID)
+		case "error":
+			method.Result.Status = StatusError
+			method.Description = fmt.Sprintf("OPA failed to evaluate resource '%s' against policy '%s'. %s", rawEv.Resource, rawEv.PolicyID, string(rawEv.Details))
+		default:
+			// Partial evaluations and undefined decisions cannot be interpreted as a pass or failure.
+			method.Result.Status = StatusUnknown
+			method.Description = fmt.Sprintf("OPA returned unrecognized decision '%s' for resource '%s' and policy '%s'.", rawEv.Decision, rawEv.Resource, rawEv.PolicyID)
 		}
 		return method
 	},
@@ -119,13 +172,29 @@ var sourceToMethod = map[string]methodMapperFunc{
 			Run:    true,
 			Result: &layer4.AssessmentResult{},
 		}
+		switch rawEv.Decision {
 		// Assume Kyverno 'mutate' implies compliance enforcement
-		if rawEv.Decision == "mutate" {
-			method.Result.Status = "COMPLIANT"
+		case "mutate":
+			method.Result.Status = StatusCompliant
 			method.Description = fmt.Sprintf("Kyverno mutated resource '%s' to enforce policy '%s'.", rawEv.Resource, rawEv.PolicyID)
-		} else if rawEv.Decision == "deny" {
-			method.Result.Status = "NOT_COMPLIANT"
+		case "pass":
+			method.Result.Status = StatusCompliant
+			method.Description = fmt.Sprintf("Kyverno validated resource '%s' against policy '%s'.", rawEv.Resource, rawEv.PolicyID)
+		case "deny", "fail":
+			method.Result.Status = StatusNotCompliant
 			method.Description = fmt.Sprintf("Kyverno denied resource '%s' due to policy '%s' violation.", rawEv.Resource, rawEv.PolicyID)
+		case "warn", "audit":
+			method.Result.Status = StatusNeedsReview
+			method.Description = fmt.Sprintf("Kyverno reported a %s result for resource '%s' against policy '%s' without enforcing it.", rawEv.Decision, rawEv.Resource, rawEv.PolicyID)
+		case "skip":
+			method.Result.Status = StatusNotApplicable
+			method.Description = fmt.Sprintf("Kyverno skipped resource '%s' for policy '%s'.", rawEv.Resource, rawEv.PolicyID)
+		case "error":
+			method.Result.Status = StatusError
+			method.Description = fmt.Sprintf("Kyverno failed to evaluate resource '%s' against policy '%s'. %s", rawEv.Resource, rawEv.PolicyID, string(rawEv.Details))
+		default:
+			method.Result.Status = StatusUnknown
+			method.Description = fmt.Sprintf("Kyverno returned unrecognized decision '%s' for resource '%s' and policy '%s'.", rawEv.Decision, rawEv.Resource, rawEv.PolicyID)
 		}
 		return method
 	},
@@ -135,12 +204,25 @@ var sourceToMethod = map[string]methodMapperFunc{
 			Run:    true,
 			Result: &layer4.AssessmentResult{},
 		}
-		if rawEv.Decision == "compliant" {
-			method.Result.Status = "COMPLIANT"
+		switch rawEv.Decision {
+		case "compliant":
+			method.Result.Status = StatusCompliant
 			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' reported compliant against profile '%s'.", rawEv.Resource, rawEv.PolicyID)
-		} else if rawEv.Decision == "non_compliant" {
-			method.Result.Status = "NOT_COMPLIANT"
+		case "non_compliant":
+			method.Result.Status = StatusNotCompliant
 			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' reported non-compliant against profile '%s'. Details: %s", rawEv.Resource, rawEv.PolicyID, string(rawEv.Details))
+		case "notapplicable":
+			method.Result.Status = StatusNotApplicable
+			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' reported profile '%s' as not applicable.", rawEv.Resource, rawEv.PolicyID)
+		case "informational", "notchecked":
+			method.Result.Status = StatusNeedsReview
+			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' reported %s results against profile '%s' that require manual review.", rawEv.Resource, rawEv.Decision, rawEv.PolicyID)
+		case "error":
+			method.Result.Status = StatusError
+			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' failed against profile '%s'. Details: %s", rawEv.Resource, rawEv.PolicyID, string(rawEv.Details))
+		default:
+			method.Result.Status = StatusUnknown
+			method.Description = fmt.Sprintf("OpenSCAP scan for '%s' returned unrecognized result '%s' against profile '%s'.", rawEv.Resource, rawEv.Decision, rawEv.PolicyID)
 		}
 		return method
 	},
@@ -0,0 +1,78 @@
+package claims
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+func TestNewFromEvidenceSources(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantErr string
+	}{
+		{source: "OPA"},
+		{source: "Kyverno"},
+		{source: "OpenSCAP"},
+		{source: "Falco", wantErr: `unsupported evidence source "Falco"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			rawEv := evidence.RawEvidence{Metadata: evidence.Metadata{ID: "ev-1", Timestamp: testTime, Source: tt.source, PolicyID: "p1", Decision: "pass"}}
+			claim, err := NewFromEvidence(rawEv, evidence.Digest([]byte("{}")), mapping.Default())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(claim.Assessment.Methods) != 1 || claim.Assessment.Methods[0].Result == nil {
+				t.Fatalf("expected one method with a result, got %+v", claim.Assessment.Methods)
+			}
+			if !claim.EvidenceTime().Equal(testTime) {
+				t.Errorf("evidence time = %s, want %s", claim.EvidenceTime(), testTime)
+			}
+		})
+	}
+}
+
+var testTime = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// testClaim returns a claim for requirement CAT.T01.TR01 on resource pod-a with a
+// single method reporting the status.
+func testClaim(id, method, status string) ConformanceClaim {
+	claim := ConformanceClaim{
+		ClaimID:        id,
+		Timestamp:      testTime,
+		ResourceRef:    "pod-a",
+		RawEvidenceRef: evidence.Digest([]byte(`{"id":"` + id + `"}`)),
+		Summary:        "Resource 'pod-a' from " + method + " is " + status + ".",
+		CatalogID:      "TEST-CAT",
+		ControlID:      "CAT.T01",
+		ConfigRevision: "abc123",
+		Assessment: layer4.Assessment{
+			RequirementID: "CAT.T01.TR01",
+			Methods: []layer4.AssessmentMethod{{
+				Name:        method,
+				Description: "simulated",
+				Run:         true,
+				Result:      &layer4.AssessmentResult{},
+			}},
+		},
+	}
+	setStatus(&claim.Assessment.Methods[0].Result.Status, status)
+	return claim
+}
+
+// statusOf returns the status of the first method of the claim.
+func statusOf(claim ConformanceClaim) string {
+	return resultStatus(claim.Assessment.Methods[0].Result)
+}
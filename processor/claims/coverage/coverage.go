@@ -0,0 +1,158 @@
+// Package coverage reports which catalog requirements are mapped to assessment methods
+// and which have received evidence.
+package coverage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// Report is the evidence coverage of a single catalog.
+type Report struct {
+	CatalogID string `json:"catalogId"`
+	// ConfigRevision is the revision of the mapping ruleset the report was built from.
+	ConfigRevision string        `json:"configRevision"`
+	GeneratedAt    time.Time     `json:"generatedAt"`
+	Requirements   []Requirement `json:"requirements"`
+	// Unmapped lists requirements with no mapped methods.
+	Unmapped []string `json:"unmapped"`
+	// Unevidenced lists requirements that have not received any evidence.
+	Unevidenced []string `json:"unevidenced"`
+	// Stale lists requirements with evidence older than its expected cadence.
+	Stale []string `json:"stale"`
+}
+
+// Requirement is the coverage of a single assessment requirement.
+type Requirement struct {
+	ControlID     string                 `json:"controlId"`
+	RequirementID string                 `json:"requirementId"`
+	Methods       []mapping.MappedMethod `json:"methods"`
+	// Sources are the evidence sources that have reported on the requirement.
+	Sources      []string   `json:"sources"`
+	Resources    int        `json:"resources"`
+	LastEvidence *time.Time `json:"lastEvidence,omitempty"`
+	// StaleSources are the evidence sources whose latest evidence for any resource is
+	// older than the expected cadence.
+	StaleSources []string `json:"staleSources"`
+}
+
+// Mapped reports whether any method is mapped to the requirement.
+func (r Requirement) Mapped() bool {
+	return len(r.Methods) > 0
+}
+
+// Evidenced reports whether any evidence was received for the requirement.
+func (r Requirement) Evidenced() bool {
+	return r.LastEvidence != nil
+}
+
+// NewReport builds the coverage report for a catalog loaded in the ruleset.
+func NewReport(ruleset *mapping.Ruleset, catalogID string, allClaims []claims.ConformanceClaim) (Report, error) {
+	catalog, ok := ruleset.Catalogs[catalogID]
+	if !ok {
+		return Report{}, fmt.Errorf("catalog %q is not loaded in ruleset %s", catalogID, ruleset.Revision)
+	}
+
+	report := Report{
+		CatalogID:      catalogID,
+		ConfigRevision: ruleset.Revision,
+		GeneratedAt:    time.Now(),
+		Unmapped:       []string{},
+		Unevidenced:    []string{},
+		Stale:          []string{},
+	}
+	for _, family := range catalog.ControlFamilies {
+		for _, control := range family.Controls {
+			for _, requirement := range control.AssessmentRequirements {
+				coverage := Requirement{
+					ControlID:     control.ID,
+					RequirementID: requirement.ID,
+					Methods:       ruleset.Methods(catalogID, requirement.ID),
+				}
+				addEvidence(&coverage, ruleset, catalogID, allClaims, report.GeneratedAt)
+
+				if !coverage.Mapped() {
+					report.Unmapped = append(report.Unmapped, requirement.ID)
+				}
+				if !coverage.Evidenced() {
+					report.Unevidenced = append(report.Unevidenced, requirement.ID)
+				}
+				if len(coverage.StaleSources) > 0 {
+					report.Stale = append(report.Stale, requirement.ID)
+				}
+				report.Requirements = append(report.Requirements, coverage)
+			}
+		}
+	}
+	return report, nil
+}
+
+// NewReports builds a coverage report for every catalog loaded in the ruleset.
+func NewReports(ruleset *mapping.Ruleset, allClaims []claims.ConformanceClaim) ([]Report, error) {
+	catalogIDs := make([]string, 0, len(ruleset.Catalogs))
+	for catalogID := range ruleset.Catalogs {
+		catalogIDs = append(catalogIDs, catalogID)
+	}
+	sort.Strings(catalogIDs)
+
+	reports := make([]Report, 0, len(catalogIDs))
+	for _, catalogID := range catalogIDs {
+		report, err := NewReport(ruleset, catalogID, allClaims)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+type sourceResource struct {
+	source, resource string
+}
+
+func addEvidence(coverage *Requirement, ruleset *mapping.Ruleset, catalogID string, allClaims []claims.ConformanceClaim, now time.Time) {
+	sources := make(map[string]struct{})
+	resources := make(map[string]struct{})
+	latest := make(map[sourceResource]time.Time)
+	for _, claim := range allClaims {
+		// Requirement IDs match case-insensitively, as they do in mapping rules.
+		if claim.CatalogID != catalogID || !strings.EqualFold(claim.Assessment.RequirementID, coverage.RequirementID) {
+			continue
+		}
+		for _, method := range claim.Assessment.Methods {
+			sources[method.Name] = struct{}{}
+			key := sourceResource{method.Name, claim.ResourceRef}
+			if claim.EvidenceTime().After(latest[key]) {
+				latest[key] = claim.EvidenceTime()
+			}
+		}
+		resources[claim.ResourceRef] = struct{}{}
+		if coverage.LastEvidence == nil || claim.EvidenceTime().After(*coverage.LastEvidence) {
+			timestamp := claim.EvidenceTime()
+			coverage.LastEvidence = &timestamp
+		}
+	}
+	coverage.Sources = make([]string, 0, len(sources))
+	for source := range sources {
+		coverage.Sources = append(coverage.Sources, source)
+	}
+	sort.Strings(coverage.Sources)
+	coverage.Resources = len(resources)
+
+	stale := make(map[string]struct{})
+	for key, timestamp := range latest {
+		if claims.IsStale(ruleset, catalogID, coverage.RequirementID, key.source, timestamp, now) {
+			stale[key.source] = struct{}{}
+		}
+	}
+	coverage.StaleSources = make([]string, 0, len(stale))
+	for source := range stale {
+		coverage.StaleSources = append(coverage.StaleSources, source)
+	}
+	sort.Strings(coverage.StaleSources)
+}
@@ -0,0 +1,179 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+const testCatalog = `metadata:
+  id: TEST-CAT
+  version: 0.1.0
+control-families:
+  - title: Example Family
+    controls:
+      - id: CAT.T01
+        assessment-requirements:
+          - id: CAT.T01.TR01
+          - id: CAT.T01.TR02
+          - id: CAT.T01.TR03
+`
+
+// TR01 and TR02 are mapped; TR03 has no method. OPA evidence is expected daily.
+const testRules = `rules:
+  - source: OPA
+    policyId: p1
+    catalogId: TEST-CAT
+    requirementId: cat.t01.tr01
+  - source: Kyverno
+    policyId: p2
+    catalogId: TEST-CAT
+    requirementId: CAT.T01.TR02
+cadences:
+  - catalogId: TEST-CAT
+    method: OPA
+    every: 24h
+`
+
+func loadRuleset(t *testing.T) *mapping.Ruleset {
+	t.Helper()
+	dir := t.TempDir()
+	catalog, rules := filepath.Join(dir, "catalog.yml"), filepath.Join(dir, "rules.yaml")
+	for path, content := range map[string]string{catalog: testCatalog, rules: testRules} {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ruleset, err := mapping.Load(mapping.Paths{Catalogs: []string{catalog}, Rules: []string{rules}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ruleset
+}
+
+func claimFor(requirementID, resource, method string, age time.Duration) claims.ConformanceClaim {
+	return claims.ConformanceClaim{
+		ClaimID:     requirementID + "/" + resource + "/" + method,
+		Timestamp:   time.Now().Add(-age),
+		ResourceRef: resource,
+		CatalogID:   "TEST-CAT",
+		Assessment: layer4.Assessment{
+			RequirementID: requirementID,
+			Methods:       []layer4.AssessmentMethod{{Name: method, Result: &layer4.AssessmentResult{}}},
+		},
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	ruleset := loadRuleset(t)
+	otherCatalog := claimFor("CAT.T01.TR02", "pod-z", "Kyverno", 0)
+	otherCatalog.CatalogID = "OTHER"
+
+	tests := []struct {
+		name        string
+		claims      []claims.ConformanceClaim
+		sources     map[string][]string
+		resources   map[string]int
+		stale       []string
+		unevidenced []string
+	}{
+		{
+			name:        "no evidence",
+			sources:     map[string][]string{"CAT.T01.TR01": {}, "CAT.T01.TR02": {}, "CAT.T01.TR03": {}},
+			stale:       []string{},
+			unevidenced: []string{"CAT.T01.TR01", "CAT.T01.TR02", "CAT.T01.TR03"},
+		},
+		{
+			name: "requirement IDs match case-insensitively",
+			claims: []claims.ConformanceClaim{
+				claimFor("cat.t01.tr01", "pod-a", "OPA", time.Hour),
+				claimFor("CAT.T01.TR01", "pod-b", "OPA", time.Hour),
+				otherCatalog,
+			},
+			sources:     map[string][]string{"CAT.T01.TR01": {"OPA"}, "CAT.T01.TR02": {}, "CAT.T01.TR03": {}},
+			resources:   map[string]int{"CAT.T01.TR01": 2},
+			stale:       []string{},
+			unevidenced: []string{"CAT.T01.TR02", "CAT.T01.TR03"},
+		},
+		{
+			name: "stale method",
+			claims: []claims.ConformanceClaim{
+				claimFor("CAT.T01.TR01", "pod-a", "OPA", 48*time.Hour),
+				claimFor("CAT.T01.TR01", "pod-b", "OPA", time.Hour),
+				// Kyverno has no cadence, so old evidence is never stale.
+				claimFor("CAT.T01.TR02", "pod-a", "Kyverno", 480*time.Hour),
+			},
+			sources:     map[string][]string{"CAT.T01.TR01": {"OPA"}, "CAT.T01.TR02": {"Kyverno"}, "CAT.T01.TR03": {}},
+			resources:   map[string]int{"CAT.T01.TR01": 2, "CAT.T01.TR02": 1},
+			stale:       []string{"CAT.T01.TR01"},
+			unevidenced: []string{"CAT.T01.TR03"},
+		},
+		{
+			name:        "evidence for a requirement with no method",
+			claims:      []claims.ConformanceClaim{claimFor("CAT.T01.TR03", "pod-a", "OpenSCAP", 0)},
+			sources:     map[string][]string{"CAT.T01.TR01": {}, "CAT.T01.TR02": {}, "CAT.T01.TR03": {"OpenSCAP"}},
+			resources:   map[string]int{"CAT.T01.TR03": 1},
+			stale:       []string{},
+			unevidenced: []string{"CAT.T01.TR01", "CAT.T01.TR02"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := NewReport(ruleset, "TEST-CAT", tt.claims)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.ConfigRevision != ruleset.Revision {
+				t.Errorf("config revision = %s, want %s", report.ConfigRevision, ruleset.Revision)
+			}
+			// Requirements without a method are reported unmapped whether or not evidence arrived.
+			if want := []string{"CAT.T01.TR03"}; !reflect.DeepEqual(report.Unmapped, want) {
+				t.Errorf("unmapped = %v, want %v", report.Unmapped, want)
+			}
+			if !reflect.DeepEqual(report.Unevidenced, tt.unevidenced) {
+				t.Errorf("unevidenced = %v, want %v", report.Unevidenced, tt.unevidenced)
+			}
+			if !reflect.DeepEqual(report.Stale, tt.stale) {
+				t.Errorf("stale = %v, want %v", report.Stale, tt.stale)
+			}
+			for _, requirement := range report.Requirements {
+				if want := tt.sources[requirement.RequirementID]; !reflect.DeepEqual(requirement.Sources, want) {
+					t.Errorf("%s sources = %v, want %v", requirement.RequirementID, requirement.Sources, want)
+				}
+				if want := tt.resources[requirement.RequirementID]; requirement.Resources != want {
+					t.Errorf("%s resources = %d, want %d", requirement.RequirementID, requirement.Resources, want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewReportMethods(t *testing.T) {
+	report, err := NewReport(loadRuleset(t), "TEST-CAT", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]mapping.MappedMethod{
+		"CAT.T01.TR01": {{Source: "OPA", PolicyID: "p1"}},
+		"CAT.T01.TR02": {{Source: "Kyverno", PolicyID: "p2"}},
+		"CAT.T01.TR03": {},
+	}
+	for _, requirement := range report.Requirements {
+		if !reflect.DeepEqual(requirement.Methods, want[requirement.RequirementID]) {
+			t.Errorf("%s methods = %+v, want %+v", requirement.RequirementID, requirement.Methods, want[requirement.RequirementID])
+		}
+		if requirement.ControlID != "CAT.T01" {
+			t.Errorf("%s control = %s, want CAT.T01", requirement.RequirementID, requirement.ControlID)
+		}
+	}
+	if _, err := NewReport(loadRuleset(t), "MISSING", nil); err == nil {
+		t.Error("expected an error for a catalog that is not loaded")
+	}
+}
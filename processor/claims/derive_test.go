@@ -0,0 +1,70 @@
+package claims
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+func TestDerive(t *testing.T) {
+	native := testClaim("native-1", "OPA", StatusNotCompliant)
+	native.Provenance = &Provenance{EvidenceDigest: native.RawEvidenceRef, Mapping: "rule OPA/p1", MappingRevision: "aaa", CatalogVersion: "0.1.0"}
+	derivation := mapping.Derivation{
+		Target:          mapping.Target{CatalogID: "NIST-800-53", ControlID: "CM-7", RequirementID: "CM-7(5)"},
+		Strength:        8,
+		Mapping:         "crosswalk TEST-CAT/CAT.T01.TR01",
+		MappingRevision: "bbb",
+		CatalogVersion:  "5.1",
+	}
+
+	derived := Derive(native, derivation)
+	if derived.ClaimID == "" || derived.ClaimID == native.ClaimID {
+		t.Errorf("derived claim ID = %q, want a new ID", derived.ClaimID)
+	}
+	if derived.DerivedFrom != native.ClaimID || derived.MappingStrength != 8 {
+		t.Errorf("derived from %q with strength %d, want %s with 8", derived.DerivedFrom, derived.MappingStrength, native.ClaimID)
+	}
+	if derived.CatalogID != "NIST-800-53" || derived.ControlID != "CM-7" || derived.Assessment.RequirementID != "CM-7(5)" {
+		t.Errorf("target = %s/%s/%s", derived.CatalogID, derived.ControlID, derived.Assessment.RequirementID)
+	}
+	if statusOf(*derived) != StatusNotCompliant || derived.ResourceRef != native.ResourceRef || derived.RawEvidenceRef != native.RawEvidenceRef {
+		t.Errorf("derived claim does not carry the native result and evidence: %+v", derived)
+	}
+	if p := derived.Provenance; p.Mapping != derivation.Mapping || p.MappingRevision != "bbb" || p.CatalogVersion != "5.1" || p.EvidenceDigest != native.RawEvidenceRef {
+		t.Errorf("provenance = %+v", p)
+	}
+	if native.Provenance.Mapping != "rule OPA/p1" || native.Assessment.RequirementID != "CAT.T01.TR01" {
+		t.Error("Derive modified the native claim")
+	}
+}
+
+func TestDerivedClaimsKeepNativePosture(t *testing.T) {
+	store := NewMemoryStore()
+	native := testClaim("native-1", "OPA", StatusCompliant)
+	derived := Derive(native, mapping.Derivation{
+		Target:   mapping.Target{CatalogID: "NIST-800-53", ControlID: "CM-7", RequirementID: "CM-7(5)"},
+		Strength: 8,
+	})
+	for _, claim := range []ConformanceClaim{native, *derived} {
+		if err := store.Add(claim); err != nil {
+			t.Fatal(err)
+		}
+	}
+	current, err := store.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, claim := range current {
+		got = append(got, claim.CatalogID+"/"+claim.ClaimID)
+	}
+	slices.Sort(got)
+	if want := []string{"NIST-800-53/" + derived.ClaimID, "TEST-CAT/native-1"}; !slices.Equal(got, want) {
+		t.Errorf("current = %v, want %v", got, want)
+	}
+	stored, _, err := store.Get("native-1")
+	if err != nil || stored.SupersededBy != "" {
+		t.Errorf("native claim superseded by %q, err %v", stored.SupersededBy, err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package drift detects changes in requirement verdicts over time, so regressions can be
+// alerted on and time to remediate can be measured.
+package drift
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+)
+
+// Kind classifies a transition.
+type Kind string
+
+const (
+	// Regression is a transition into NOT_COMPLIANT.
+	Regression Kind = "regression"
+	// Remediation is a transition to a passing status after a regression, including
+	// regressions that passed through unresolved statuses on the way.
+	Remediation Kind = "remediation"
+	// Change is any other transition, such as into or out of an unresolved status.
+	Change Kind = "change"
+)
+
+// Transition is a change in the verdict for a requirement on a resource.
+type Transition struct {
+	Kind          Kind      `json:"kind"`
+	Timestamp     time.Time `json:"timestamp"`
+	Tenant        string    `json:"tenant"`
+	CatalogID     string    `json:"catalogId"`
+	RequirementID string    `json:"requirementId"`
+	ResourceRef   string    `json:"resourceRef"`
+	From          string    `json:"from"`
+	To            string    `json:"to"`
+	// Before holds the current claims for the requirement and resource before the change.
+	Before []claims.ConformanceClaim `json:"before"`
+	// After is the claim that caused the change.
+	After claims.ConformanceClaim `json:"after"`
+	// TimeToRemediateSeconds is set on remediations whose regression was observed by this agent.
+	TimeToRemediateSeconds float64 `json:"timeToRemediateSeconds,omitempty"`
+}
+
+func passing(status string) bool {
+	return status == claims.StatusCompliant || status == claims.StatusWaived || status == claims.StatusNotApplicable
+}
+
+type requirementKey struct {
+	tenant, catalogID, requirementID, resourceRef string
+}
+
+// Detector compares verdicts before and after a claim is stored.
+type Detector struct {
+	aggregator *aggregate.Aggregator
+
+	mu sync.Mutex
+	// regressedAt records when each open regression started.
+	regressedAt map[requirementKey]time.Time
+}
+
+// NewDetector creates a Detector that computes verdicts with the aggregator.
+func NewDetector(aggregator *aggregate.Aggregator) *Detector {
+	return &Detector{
+		aggregator:  aggregator,
+		regressedAt: make(map[requirementKey]time.Time),
+	}
+}
+
+// Detect returns the transition caused by the claim, given the current claims for its
+// requirement and resource before and after it was stored. The first verdict for a
+// requirement and resource is not a transition.
+func (d *Detector) Detect(claim claims.ConformanceClaim, before, after []claims.ConformanceClaim) (Transition, bool) {
+	from, ok := d.verdict(claim, before)
+	if !ok {
+		return Transition{}, false
+	}
+	to, ok := d.verdict(claim, after)
+	if !ok || from.Status == to.Status {
+		return Transition{}, false
+	}
+
+	transition := Transition{
+		Kind:          Change,
+		Timestamp:     claim.Timestamp,
+		Tenant:        claim.TenantID(),
+		CatalogID:     claim.CatalogID,
+		RequirementID: claim.Assessment.RequirementID,
+		ResourceRef:   claim.ResourceRef,
+		From:          from.Status,
+		To:            to.Status,
+		Before:        before,
+		After:         claim,
+	}
+
+	key := requirementKey{claim.TenantID(), claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	start, open := d.regressedAt[key]
+	switch {
+	case to.Status == claims.StatusNotCompliant && !open:
+		transition.Kind = Regression
+		d.regressedAt[key] = claim.Timestamp
+	case passing(to.Status) && (open || from.Status == claims.StatusNotCompliant):
+		transition.Kind = Remediation
+		if open {
+			transition.TimeToRemediateSeconds = claim.Timestamp.Sub(start).Seconds()
+			delete(d.regressedAt, key)
+		}
+	}
+	return transition, true
+}
+
+// Prune forgets open regressions for requirements and resources with no claim in the
+// current posture, such as resources whose claims were evicted or that are no longer
+// assessed. It returns the number of regressions forgotten.
+func (d *Detector) Prune(current []claims.ConformanceClaim) int {
+	keys := make(map[requirementKey]bool, len(current))
+	for _, claim := range current {
+		keys[requirementKey{claim.TenantID(), claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef}] = true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pruned := 0
+	for key := range d.regressedAt {
+		if !keys[key] {
+			delete(d.regressedAt, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+// verdict returns the verdict for the claim's requirement and resource.
+func (d *Detector) verdict(claim claims.ConformanceClaim, current []claims.ConformanceClaim) (aggregate.Verdict, bool) {
+	for _, verdict := range d.aggregator.Aggregate(current) {
+		if verdict.Tenant == claim.TenantID() &&
+			verdict.CatalogID == claim.CatalogID &&
+			verdict.RequirementID == claim.Assessment.RequirementID &&
+			verdict.ResourceRef == claim.ResourceRef {
+			return verdict, true
+		}
+	}
+	return aggregate.Verdict{}, false
+}
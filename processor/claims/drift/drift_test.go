@@ -0,0 +1,169 @@
+package drift
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+)
+
+var epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// claimAt returns an OPA claim for requirement AC-1 on the resource, made hours after epoch.
+func claimAt(resource string, hours int, status string) claims.ConformanceClaim {
+	method := layer4.AssessmentMethod{Name: "OPA", Result: &layer4.AssessmentResult{}}
+	setStatus(&method.Result.Status, status)
+	return claims.ConformanceClaim{
+		ClaimID:     fmt.Sprintf("%s-%d", resource, hours),
+		Timestamp:   epoch.Add(time.Duration(hours) * time.Hour),
+		ResourceRef: resource,
+		CatalogID:   "NIST-800-53",
+		Assessment:  layer4.Assessment{RequirementID: "AC-1", Methods: []layer4.AssessmentMethod{method}},
+	}
+}
+
+func setStatus[T ~string](dst *T, status string) {
+	*dst = T(status)
+}
+
+func newDetector(t *testing.T) *Detector {
+	t.Helper()
+	aggregator, err := aggregate.New(aggregate.AllMustPass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewDetector(aggregator)
+}
+
+func TestDetect(t *testing.T) {
+	// step is the expected outcome of storing one claim; kind is empty when the claim
+	// causes no transition, and verdict defaults to the claim's status.
+	type step struct {
+		status  string
+		kind    Kind
+		ttr     float64
+		verdict string
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "first verdict is not a transition",
+			steps: []step{{status: claims.StatusNotCompliant}},
+		},
+		{
+			name:  "same verdict is not a transition",
+			steps: []step{{status: claims.StatusCompliant}, {status: claims.StatusCompliant}},
+		},
+		{
+			name: "compliant to not compliant is a regression",
+			steps: []step{
+				{status: claims.StatusCompliant},
+				{status: claims.StatusNotCompliant, kind: Regression},
+			},
+		},
+		{
+			name: "remediation through an unresolved status",
+			steps: []step{
+				{status: claims.StatusCompliant},
+				{status: claims.StatusNotCompliant, kind: Regression},
+				{status: claims.StatusError, kind: Change},
+				{status: claims.StatusCompliant, kind: Remediation, ttr: (2 * time.Hour).Seconds()},
+			},
+		},
+		{
+			name: "waived counts as passing",
+			steps: []step{
+				{status: claims.StatusCompliant},
+				{status: claims.StatusNotCompliant, kind: Regression},
+				{status: claims.StatusWaived, kind: Remediation, ttr: time.Hour.Seconds(), verdict: claims.StatusCompliant},
+				{status: claims.StatusCompliant},
+			},
+		},
+		{
+			name: "remediation of an unobserved regression has no time to remediate",
+			steps: []step{
+				{status: claims.StatusNotCompliant},
+				{status: claims.StatusCompliant, kind: Remediation},
+			},
+		},
+		{
+			name: "unresolved status after passing is a change",
+			steps: []step{
+				{status: claims.StatusCompliant},
+				{status: claims.StatusNeedsReview, kind: Change},
+				{status: claims.StatusCompliant, kind: Change},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newDetector(t)
+			var before []claims.ConformanceClaim
+			for i, s := range tt.steps {
+				claim := claimAt("pod-a", i, s.status)
+				after := []claims.ConformanceClaim{claim}
+				transition, ok := detector.Detect(claim, before, after)
+				before = after
+
+				if ok != (s.kind != "") {
+					t.Fatalf("step %d (%s): transition = %v, want %v", i, s.status, ok, s.kind != "")
+				}
+				if !ok {
+					continue
+				}
+				if transition.Kind != s.kind {
+					t.Errorf("step %d (%s): kind = %s, want %s", i, s.status, transition.Kind, s.kind)
+				}
+				verdict := s.verdict
+				if verdict == "" {
+					verdict = s.status
+				}
+				if transition.To != verdict || transition.After.ClaimID != claim.ClaimID {
+					t.Errorf("step %d: transition to %s by %s, want %s by %s", i, transition.To, transition.After.ClaimID, verdict, claim.ClaimID)
+				}
+				if transition.TimeToRemediateSeconds != s.ttr {
+					t.Errorf("step %d (%s): time to remediate = %v, want %v", i, s.status, transition.TimeToRemediateSeconds, s.ttr)
+				}
+			}
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	detector := newDetector(t)
+	for _, resource := range []string{"pod-a", "pod-b"} {
+		before := []claims.ConformanceClaim{claimAt(resource, 0, claims.StatusCompliant)}
+		after := []claims.ConformanceClaim{claimAt(resource, 1, claims.StatusNotCompliant)}
+		if transition, _ := detector.Detect(after[0], before, after); transition.Kind != Regression {
+			t.Fatalf("expected a regression for %s, got %q", resource, transition.Kind)
+		}
+	}
+
+	current := []claims.ConformanceClaim{claimAt("pod-a", 1, claims.StatusNotCompliant)}
+	if pruned := detector.Prune(current); pruned != 1 {
+		t.Errorf("pruned %d regressions, want 1", pruned)
+	}
+	if pruned := detector.Prune(current); pruned != 0 {
+		t.Errorf("pruned %d regressions on the second pass, want 0", pruned)
+	}
+
+	// The open regression for pod-a is kept and measured on remediation.
+	claim := claimAt("pod-a", 3, claims.StatusCompliant)
+	transition, ok := detector.Detect(claim, current, []claims.ConformanceClaim{claim})
+	if !ok || transition.Kind != Remediation || transition.TimeToRemediateSeconds != (2*time.Hour).Seconds() {
+		t.Errorf("pod-a: got %+v (ok %v), want remediation after 2h", transition, ok)
+	}
+
+	// pod-b was forgotten, so its regression is reported again when it reappears.
+	before := []claims.ConformanceClaim{claimAt("pod-b", 4, claims.StatusCompliant)}
+	after := []claims.ConformanceClaim{claimAt("pod-b", 5, claims.StatusNotCompliant)}
+	if transition, _ := detector.Detect(after[0], before, after); transition.Kind != Regression {
+		t.Errorf("pod-b: kind = %q, want regression", transition.Kind)
+	}
+}
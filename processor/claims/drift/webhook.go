@@ -0,0 +1,78 @@
+package drift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// DefaultQueueSize is how many transitions a Webhook buffers before dropping new ones.
+const DefaultQueueSize = 100
+
+// Webhook posts transitions as JSON to a URL. Transitions are queued by Notify and
+// posted in order by Run, so a slow endpoint does not delay claim processing.
+type Webhook struct {
+	url    string
+	client *http.Client
+	queue  chan Transition
+}
+
+// NewWebhook creates a Webhook that posts to the URL and buffers up to DefaultQueueSize transitions.
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		queue:  make(chan Transition, DefaultQueueSize),
+	}
+}
+
+// Notify queues the transition for Run to post. It never blocks; when the queue is
+// full the transition is dropped and Notify returns false.
+func (w *Webhook) Notify(transition Transition) bool {
+	select {
+	case w.queue <- transition:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run posts queued transitions until the context is canceled. Failed posts are logged.
+func (w *Webhook) Run(ctx context.Context) {
+	for {
+		select {
+		case transition := <-w.queue:
+			if err := w.Send(ctx, transition); err != nil {
+				log.Printf("Error sending transition for claim %s: %v", transition.After.ClaimID, err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Send posts the transition. Any non-2xx response is an error.
+func (w *Webhook) Send(ctx context.Context, transition Transition) error {
+	body, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send transition to %s: %w", w.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("transition webhook %s returned %s", w.url, resp.Status)
+	}
+	return nil
+}
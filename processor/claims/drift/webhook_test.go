@@ -0,0 +1,67 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+func TestWebhookPostsQueuedTransitions(t *testing.T) {
+	received := make(chan Transition, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var transition Transition
+		if err := json.NewDecoder(r.Body).Decode(&transition); err != nil {
+			t.Errorf("decode transition: %v", err)
+		}
+		received <- transition
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL)
+	for _, id := range []string{"c1", "c2"} {
+		if !webhook.Notify(Transition{Kind: Regression, After: claims.ConformanceClaim{ClaimID: id}}) {
+			t.Fatalf("transition for %s was dropped", id)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go webhook.Run(ctx)
+
+	for _, want := range []string{"c1", "c2"} {
+		select {
+		case transition := <-received:
+			if transition.After.ClaimID != want || transition.Kind != Regression {
+				t.Errorf("received %s %s, want regression for %s", transition.Kind, transition.After.ClaimID, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("transition for %s was not posted", want)
+		}
+	}
+}
+
+func TestWebhookNotifyDoesNotBlock(t *testing.T) {
+	webhook := NewWebhook("http://127.0.0.1:0")
+	for i := 0; i < DefaultQueueSize; i++ {
+		if !webhook.Notify(Transition{}) {
+			t.Fatalf("transition %d was dropped before the queue was full", i)
+		}
+	}
+	if webhook.Notify(Transition{}) {
+		t.Error("expected the transition to be dropped when the queue is full")
+	}
+}
+
+func TestWebhookSendFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	if err := NewWebhook(server.URL).Send(context.Background(), Transition{}); err == nil {
+		t.Error("expected an error for a 502 response")
+	}
+}
@@ -1,16 +1,23 @@
 package evidence
 
 import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
+	"github.com/cyberphone/json-canonicalization/go/src/webpki.org/jsoncanonicalizer"
 	"github.com/in-toto/go-witness/cryptoutil"
 )
 
 // RawEvidence represents a simplified raw output from a policy engine.
 type RawEvidence struct {
-	Metadata `json:,inline`
+	Metadata
 	Details  json.RawMessage `json:"details"`
 	Resource Resource        `json:"resource"`
 }
@@ -21,6 +28,20 @@ type Metadata struct {
 	Source    string    `json:"source"`
 	PolicyID  string    `json:"policyId"`
 	Decision  string    `json:"decision"`
+	// Tenant is the cluster or team reporting the evidence. Evidence without a tenant
+	// belongs to DefaultTenant.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// DefaultTenant is the tenant of evidence and claims that do not name one.
+const DefaultTenant = "default"
+
+// TenantName returns the tenant, or DefaultTenant when it is empty.
+func TenantName(tenant string) string {
+	if tenant == "" {
+		return DefaultTenant
+	}
+	return tenant
 }
 
 type Resource struct {
@@ -28,9 +49,96 @@ type Resource struct {
 	Digest cryptoutil.DigestSet `json:"digest"`
 }
 
-// Export simulates exporting evidence to a backend.
-// In a real scenario, this would be client.PutObject(rawEvJSON, rawEvidenceRef) to object storage.
+var (
+	// ErrNotFound is returned when no evidence is stored under a reference.
+	ErrNotFound = errors.New("evidence not found")
+	// ErrInvalidRef is returned for references a store cannot address.
+	ErrInvalidRef = errors.New("invalid evidence reference")
+)
+
+// Store persists raw evidence documents under the reference recorded on claims.
+type Store interface {
+	// Put stores the document under the reference. Evidence is immutable, so storing a
+	// reference that already exists leaves the stored document unchanged.
+	Put(ref string, data []byte) error
+	// Get returns the document stored under the reference, or ErrNotFound.
+	Get(ref string) ([]byte, error)
+}
+
+// RefAlgorithm is the digest algorithm of evidence references.
+var RefAlgorithm = cryptoutil.DigestValue{Hash: crypto.SHA256}
+
+// Marshal encodes raw evidence as RFC 8785 canonical JSON, so the document and its
+// reference do not depend on how the evidence was serialized.
+func Marshal(rawEv RawEvidence) ([]byte, error) {
+	data, err := json.Marshal(rawEv)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(data)
+}
+
+// Canonicalize returns the RFC 8785 canonical form of a JSON document: sorted keys,
+// no insignificant whitespace, and normalized strings and numbers.
+func Canonicalize(data []byte) ([]byte, error) {
+	canonical, err := jsoncanonicalizer.Transform(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize JSON: %w", err)
+	}
+	return canonical, nil
+}
+
+// Digest returns the reference of a stored document: the sha256 digest of its content
+// as sha256:<hex>.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return FormatRef(hex.EncodeToString(sum[:]))
+}
+
+// Digests returns the digest of the canonical JSON of a document for each algorithm,
+// so third parties can recompute them from any serialization of the document.
+func Digests(data []byte, algorithms ...cryptoutil.DigestValue) (cryptoutil.DigestSet, error) {
+	canonical, err := Canonicalize(data)
+	if err != nil {
+		return nil, err
+	}
+	return cryptoutil.CalculateDigestSetFromBytes(canonical, algorithms)
+}
+
+// FormatRef returns the reference of a hex-encoded sha256 digest.
+func FormatRef(digest string) string {
+	return "sha256:" + digest
+}
+
+// ParseRef returns the hex-encoded sha256 digest named by the reference. References
+// recorded before they named their algorithm are bare hex digests and are accepted.
+func ParseRef(ref string) (string, error) {
+	digest := ref
+	if algorithm, value, ok := strings.Cut(ref, ":"); ok {
+		if algorithm != "sha256" {
+			return "", fmt.Errorf("%w %q: unsupported digest algorithm %s, expected sha256", ErrInvalidRef, ref, algorithm)
+		}
+		digest = value
+	}
+	if len(digest) != sha256.Size*2 || strings.ToLower(digest) != digest {
+		return "", fmt.Errorf("%w %q: expected sha256:<hex>", ErrInvalidRef, ref)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", fmt.Errorf("%w %q: expected sha256:<hex>", ErrInvalidRef, ref)
+	}
+	return digest, nil
+}
+
+// SameRef reports whether two references name the same digest.
+func SameRef(a, b string) bool {
+	digestA, errA := ParseRef(a)
+	digestB, errB := ParseRef(b)
+	return errA == nil && errB == nil && digestA == digestB
+}
+
+// Export logs evidence instead of storing it. It is used when the agent is not
+// configured with a Store.
 func Export(rawEvidenceRef string, rawEvJSON []byte) error {
-	fmt.Printf("\n--- Pushing Raw Evidence to Data Lake (%s) ---\n%s\n", rawEvidenceRef, string(rawEvJSON))
+	log.Printf("Raw evidence %s (no evidence store configured): %s", rawEvidenceRef, rawEvJSON)
 	return nil
 }
@@ -0,0 +1,146 @@
+package evidence
+
+import (
+	"crypto"
+	_ "crypto/sha512"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+)
+
+// serializations of the same evidence document, differing only in key order,
+// whitespace, and number formatting.
+var serializations = map[string]string{
+	"compact":   `{"id":"ev-1","source":"OPA","details":{"count":10,"ratio":0.5},"resource":{"name":"pod-a"}}`,
+	"reordered": `{"resource":{"name":"pod-a"},"details":{"ratio":0.5,"count":10},"source":"OPA","id":"ev-1"}`,
+	"indented":  "{\n  \"id\": \"ev-1\",\n  \"source\": \"OPA\",\n  \"details\": {\n    \"count\": 10,\n    \"ratio\": 0.5\n  },\n  \"resource\": {\"name\": \"pod-a\"}\n}\n",
+	"numbers":   `{"id":"ev-1","source":"OPA","details":{"count":1.0e1,"ratio":5E-1},"resource":{"name":"pod-a"}}`,
+}
+
+func TestDigestIsStableAcrossSerializations(t *testing.T) {
+	algorithms := []cryptoutil.DigestValue{RefAlgorithm, {Hash: crypto.SHA512}}
+	var wantRef string
+	var wantDigests cryptoutil.DigestSet
+	for name, data := range serializations {
+		canonical, err := Canonicalize([]byte(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		ref := Digest(canonical)
+		digests, err := Digests([]byte(data), algorithms...)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if wantRef == "" {
+			wantRef, wantDigests = ref, digests
+		}
+		if ref != wantRef {
+			t.Errorf("%s: digest %s, want %s", name, ref, wantRef)
+		}
+		if !reflect.DeepEqual(digests, wantDigests) {
+			t.Errorf("%s: digests %v, want %v", name, digests, wantDigests)
+		}
+		if FormatRef(digests[RefAlgorithm]) != ref {
+			t.Errorf("%s: sha256 digest %s does not match the reference %s", name, digests[RefAlgorithm], ref)
+		}
+	}
+}
+
+func TestMarshalIsCanonical(t *testing.T) {
+	rawEv := RawEvidence{
+		Metadata: Metadata{
+			ID:        "ev-1",
+			Timestamp: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+			Source:    "OPA",
+			PolicyID:  "require-labels",
+			Decision:  "pass",
+		},
+		Details:  []byte(`{ "b": 2, "a": 1 }`),
+		Resource: Resource{Name: "pod-a", Digest: cryptoutil.DigestSet{RefAlgorithm: "abc"}},
+	}
+	data, err := Marshal(rawEv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"decision":"pass","details":{"a":1,"b":2},"id":"ev-1","policyId":"require-labels","resource":{"digest":{"sha256":"abc"},"name":"pod-a"},"source":"OPA","timestamp":"2025-06-01T12:00:00Z"}`
+	if string(data) != want {
+		t.Errorf("marshal = %s\nwant %s", data, want)
+	}
+}
+
+func TestCanonicalizeRejectsInvalidJSON(t *testing.T) {
+	if _, err := Canonicalize([]byte(`{"id":`)); err == nil {
+		t.Error("expected an error canonicalizing invalid JSON")
+	}
+	if _, err := Digests([]byte(`not json`), RefAlgorithm); err == nil {
+		t.Error("expected an error digesting invalid JSON")
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	tests := []struct {
+		name string
+		ref  string
+		want string
+		err  bool
+	}{
+		{"sha256", "sha256:" + digest, digest, false},
+		{"legacy bare hex", digest, digest, false},
+		{"uppercase hex", "sha256:" + strings.ToUpper(digest), "", true},
+		{"uppercase legacy hex", strings.ToUpper(digest), "", true},
+		{"uppercase algorithm", "SHA256:" + digest, "", true},
+		{"wrong algorithm", "sha512:" + digest, "", true},
+		{"short digest", "sha256:" + digest[:62], "", true},
+		{"non-hex digest", "sha256:" + strings.Repeat("zz", 32), "", true},
+		{"path traversal", "sha256:../../" + digest[6:], "", true},
+		{"empty", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRef(tt.ref)
+			if tt.err {
+				if !errors.Is(err, ErrInvalidRef) {
+					t.Errorf("error = %v, want ErrInvalidRef", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("digest = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameRef(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	other := strings.Repeat("cd", 32)
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{"identical", "sha256:" + digest, "sha256:" + digest, true},
+		{"legacy and prefixed", digest, "sha256:" + digest, true},
+		{"prefixed and legacy", "sha256:" + digest, digest, true},
+		{"different digests", "sha256:" + digest, "sha256:" + other, false},
+		{"uppercase", "sha256:" + digest, "sha256:" + strings.ToUpper(digest), false},
+		{"wrong algorithm", "sha256:" + digest, "sha512:" + digest, false},
+		{"both invalid", "sha512:" + digest, "sha512:" + digest, false},
+		{"empty", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameRef(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameRef(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
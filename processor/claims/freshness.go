@@ -0,0 +1,64 @@
+package claims
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// EvidenceTime returns when the evidence behind the claim was reported. Claims made
+// before the evidence timestamp was recorded fall back to the claim timestamp.
+func (c ConformanceClaim) EvidenceTime() time.Time {
+	if c.Provenance != nil && c.Provenance.EvidenceTimestamp != nil {
+		return *c.Provenance.EvidenceTimestamp
+	}
+	return c.Timestamp
+}
+
+// MarkStale returns the claims with every method whose evidence is older than the cadence
+// declared in the ruleset, or whose manual attestation is not yet valid or has expired,
+// reported as STALE.
+// The given claims are not modified, so the stored record keeps the result the evidence
+// reported.
+func MarkStale(current []ConformanceClaim, ruleset *mapping.Ruleset, now time.Time) []ConformanceClaim {
+	marked := make([]ConformanceClaim, 0, len(current))
+	for _, claim := range current {
+		var methods []layer4.AssessmentMethod
+		for i, method := range claim.Assessment.Methods {
+			var reason string
+			if claim.Manual != nil && method.Name == MethodManual && now.Before(claim.Manual.ValidFrom) {
+				reason = fmt.Sprintf("Attestation is not valid until %s.", claim.Manual.ValidFrom.Format(time.RFC3339))
+			} else if claim.Manual != nil && method.Name == MethodManual && !now.Before(claim.Manual.ValidUntil) {
+				reason = fmt.Sprintf("Attestation expired at %s.", claim.Manual.ValidUntil.Format(time.RFC3339))
+			} else if cadence, ok := ruleset.Cadence(claim.CatalogID, claim.Assessment.RequirementID, method.Name); ok && now.Sub(claim.EvidenceTime()) > cadence {
+				reason = fmt.Sprintf("No %s evidence since %s, expected every %s.",
+					method.Name, claim.EvidenceTime().Format(time.RFC3339), cadence)
+			} else {
+				continue
+			}
+			if methods == nil {
+				methods = append([]layer4.AssessmentMethod(nil), claim.Assessment.Methods...)
+			}
+			stale := method
+			stale.Result = &layer4.AssessmentResult{}
+			setStatus(&stale.Result.Status, StatusStale)
+			stale.Description = strings.TrimSpace(reason + " " + method.Description)
+			methods[i] = stale
+		}
+		if methods != nil {
+			claim.Assessment.Methods = methods
+		}
+		marked = append(marked, claim)
+	}
+	return marked
+}
+
+// IsStale reports whether evidence from the method at the given time is older than its cadence.
+func IsStale(ruleset *mapping.Ruleset, catalogID, requirementID, method string, timestamp, now time.Time) bool {
+	cadence, ok := ruleset.Cadence(catalogID, requirementID, method)
+	return ok && now.Sub(timestamp) > cadence
+}
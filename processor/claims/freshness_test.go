@@ -0,0 +1,78 @@
+package claims
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+func TestMarkStale(t *testing.T) {
+	ruleset := &mapping.Ruleset{Cadences: []mapping.Cadence{
+		{CatalogID: "TEST-CAT", Every: 168 * time.Hour},
+		{CatalogID: "TEST-CAT", Method: "OpenSCAP", Every: 24 * time.Hour},
+	}}
+	withEvidenceAge := func(claim ConformanceClaim, age time.Duration) ConformanceClaim {
+		reported := testTime.Add(-age)
+		claim.Provenance = &Provenance{EvidenceTimestamp: &reported}
+		return claim
+	}
+	manual := func(validUntil time.Time) ConformanceClaim {
+		claim := testClaim("m1", MethodManual, StatusCompliant)
+		claim.Manual = &ManualAttestation{ValidFrom: testTime.Add(-time.Hour), ValidUntil: validUntil}
+		return claim
+	}
+	otherCatalog := testClaim("c1", "OPA", StatusCompliant)
+	otherCatalog.CatalogID = "OTHER"
+
+	tests := []struct {
+		name   string
+		claim  ConformanceClaim
+		age    time.Duration
+		status string
+		reason string
+	}{
+		{name: "within catalog cadence", claim: testClaim("c1", "OPA", StatusCompliant), age: 100 * time.Hour, status: StatusCompliant},
+		{name: "beyond catalog cadence", claim: testClaim("c1", "OPA", StatusCompliant), age: 200 * time.Hour, status: StatusStale, reason: "No OPA evidence since"},
+		{name: "method cadence overrides catalog", claim: testClaim("c1", "OpenSCAP", StatusCompliant), age: 25 * time.Hour, status: StatusStale, reason: "expected every 24h0m0s"},
+		{name: "failures go stale too", claim: testClaim("c1", "OPA", StatusNotCompliant), age: 200 * time.Hour, status: StatusStale},
+		{name: "no cadence", claim: otherCatalog, age: 1000 * time.Hour, status: StatusCompliant},
+		{name: "late evidence is measured from its timestamp", claim: withEvidenceAge(testClaim("c1", "OPA", StatusCompliant), 200*time.Hour), status: StatusStale},
+		{name: "fresh evidence on an old claim", claim: withEvidenceAge(testClaim("c1", "OPA", StatusCompliant), -199*time.Hour), age: 200 * time.Hour, status: StatusCompliant},
+		{name: "valid attestation", claim: manual(testTime.Add(time.Hour)), status: StatusCompliant},
+		{name: "expired attestation", claim: manual(testTime), status: StatusStale, reason: "Attestation expired at"},
+		{name: "attestation not yet valid", claim: manual(testTime.Add(3 * time.Hour)), age: -2 * time.Hour, status: StatusStale, reason: "Attestation is not valid until"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := tt.claim
+			now := testTime.Add(tt.age)
+			marked := MarkStale([]ConformanceClaim{original}, ruleset, now)
+			if len(marked) != 1 {
+				t.Fatalf("got %d claims, want 1", len(marked))
+			}
+			if got := statusOf(marked[0]); got != tt.status {
+				t.Errorf("status = %s, want %s", got, tt.status)
+			}
+			if description := marked[0].Assessment.Methods[0].Description; !strings.Contains(description, tt.reason) {
+				t.Errorf("description = %q, want it to contain %q", description, tt.reason)
+			}
+			if statusOf(original) == StatusStale {
+				t.Error("MarkStale modified the stored claim")
+			}
+		})
+	}
+}
+
+func TestEvidenceTime(t *testing.T) {
+	claim := testClaim("c1", "OPA", StatusCompliant)
+	if !claim.EvidenceTime().Equal(claim.Timestamp) {
+		t.Errorf("evidence time without provenance = %s, want the claim timestamp", claim.EvidenceTime())
+	}
+	reported := testTime.Add(-time.Hour)
+	claim.Provenance = &Provenance{EvidenceTimestamp: &reported}
+	if !claim.EvidenceTime().Equal(reported) {
+		t.Errorf("evidence time = %s, want %s", claim.EvidenceTime(), reported)
+	}
+}
@@ -0,0 +1,228 @@
+package claims
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/in-toto/go-witness/cryptoutil"
+	"github.com/in-toto/go-witness/dsse"
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+	"github.com/jpower432/shiny-journey/processor/version"
+)
+
+const (
+	// MethodManual is the assessment method of claims attested by a person.
+	MethodManual = "manual"
+	// AttestationPayloadType is the DSSE payload type of a manual attestation.
+	AttestationPayloadType = "application/vnd.shiny-journey.manual-attestation.v1+json"
+)
+
+var (
+	// ErrUntrustedAttestation is returned when a manual attestation is not signed by a
+	// trusted attester key.
+	ErrUntrustedAttestation = errors.New("attestation is not signed by a trusted attester")
+	// ErrInvalidAttestation is returned when a manual attestation is incomplete, expired,
+	// or references an unknown requirement.
+	ErrInvalidAttestation = errors.New("invalid attestation")
+)
+
+// manualStatuses are the results a person may attest to.
+var manualStatuses = []string{StatusCompliant, StatusNotCompliant, StatusNotApplicable, StatusNeedsReview}
+
+// ManualAttestation is a signed statement by a person that a requirement is met, for
+// requirements a policy engine cannot evidence, such as reviewed procedures.
+type ManualAttestation struct {
+	CatalogID     string `json:"catalogId"`
+	RequirementID string `json:"requirementId"`
+	ResourceRef   string `json:"resourceRef"`
+	// Tenant is the cluster or team the attestation is made for. Empty is the default tenant.
+	Tenant string `json:"tenant,omitempty"`
+	// Status is the attested result, one of COMPLIANT, NOT_COMPLIANT, NOT_APPLICABLE, or NEEDS_REVIEW.
+	Status    string     `json:"status"`
+	Statement string     `json:"statement"`
+	Attester  Attester   `json:"attester"`
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+	// ValidFrom and ValidUntil bound the period the attestation holds. The claim is
+	// reported as STALE once it expires.
+	ValidFrom  time.Time `json:"validFrom"`
+	ValidUntil time.Time `json:"validUntil"`
+	// KeyID identifies the key that signed the attestation. It is set on verification.
+	KeyID string `json:"keyId,omitempty"`
+}
+
+// Attester identifies the person making an attestation.
+type Attester struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+func (a Attester) String() string {
+	if a.Email == "" {
+		return a.Name
+	}
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// Artifact is a document supporting an attestation, such as a review record.
+type Artifact struct {
+	Name string `json:"name"`
+	URI  string `json:"uri"`
+	// Digest is the digest of the artifact content, e.g. sha256:<hex>.
+	Digest string `json:"digest,omitempty"`
+}
+
+// Validate checks that the attestation is complete and valid at the given time.
+func (a ManualAttestation) Validate(now time.Time) error {
+	var errs []error
+	if a.CatalogID == "" || a.RequirementID == "" || a.ResourceRef == "" {
+		errs = append(errs, errors.New("catalogId, requirementId, and resourceRef are required"))
+	}
+	if !validManualStatus(a.Status) {
+		errs = append(errs, fmt.Errorf("status must be one of %s", strings.Join(manualStatuses, ", ")))
+	}
+	if a.Statement == "" {
+		errs = append(errs, errors.New("statement is required"))
+	}
+	if a.Attester.Name == "" {
+		errs = append(errs, errors.New("attester name is required"))
+	}
+	for _, artifact := range a.Artifacts {
+		if artifact.Name == "" || artifact.URI == "" {
+			errs = append(errs, errors.New("artifacts require a name and uri"))
+			break
+		}
+	}
+	switch {
+	case a.ValidFrom.IsZero() || a.ValidUntil.IsZero():
+		errs = append(errs, errors.New("validFrom and validUntil are required"))
+	case !a.ValidUntil.After(a.ValidFrom):
+		errs = append(errs, errors.New("validUntil must be after validFrom"))
+	case now.Before(a.ValidFrom):
+		errs = append(errs, fmt.Errorf("attestation is not valid until %s", a.ValidFrom.Format(time.RFC3339)))
+	case !now.Before(a.ValidUntil):
+		errs = append(errs, fmt.Errorf("attestation expired at %s", a.ValidUntil.Format(time.RFC3339)))
+	}
+	return errors.Join(errs...)
+}
+
+func validManualStatus(status string) bool {
+	for _, allowed := range manualStatuses {
+		if status == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// SignAttestation signs the attestation into a DSSE envelope.
+func SignAttestation(attestation ManualAttestation, signers ...cryptoutil.Signer) (dsse.Envelope, error) {
+	attestation.KeyID = ""
+	payload, err := json.Marshal(attestation)
+	if err != nil {
+		return dsse.Envelope{}, err
+	}
+	return dsse.Sign(AttestationPayloadType, bytes.NewReader(payload), dsse.SignWithSigners(signers...))
+}
+
+// VerifyAttestation verifies that the envelope is signed by one of the trusted attester
+// keys and returns the attestation it carries.
+func VerifyAttestation(envelope dsse.Envelope, verifiers ...cryptoutil.Verifier) (ManualAttestation, error) {
+	if envelope.PayloadType != AttestationPayloadType {
+		return ManualAttestation{}, fmt.Errorf("%w: unexpected payload type %q, expected %s", ErrInvalidAttestation, envelope.PayloadType, AttestationPayloadType)
+	}
+	if len(verifiers) == 0 {
+		return ManualAttestation{}, fmt.Errorf("%w: no attester keys are configured", ErrUntrustedAttestation)
+	}
+	passed, err := envelope.Verify(dsse.VerifyWithVerifiers(verifiers...))
+	if err != nil {
+		return ManualAttestation{}, fmt.Errorf("%w: %v", ErrUntrustedAttestation, err)
+	}
+	var attestation ManualAttestation
+	if err := json.Unmarshal(envelope.Payload, &attestation); err != nil {
+		return ManualAttestation{}, fmt.Errorf("%w: %w", ErrInvalidAttestation, err)
+	}
+	attestation.KeyID = ""
+	for _, checked := range passed {
+		if checked.Error != nil || checked.Verifier == nil {
+			continue
+		}
+		if keyID, err := checked.Verifier.KeyID(); err == nil {
+			attestation.KeyID = keyID
+			break
+		}
+	}
+	return attestation, nil
+}
+
+// NewFromAttestation creates a claim with a single manual method from a verified
+// attestation. The evidence reference identifies the signed attestation envelope.
+func NewFromAttestation(attestation ManualAttestation, evidenceRef string, ruleset *mapping.Ruleset) (*ConformanceClaim, error) {
+	if err := attestation.Validate(time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidAttestation, err)
+	}
+	catalog, ok := ruleset.Catalogs[attestation.CatalogID]
+	if !ok && ruleset.Revision != mapping.BuiltinRevision {
+		return nil, fmt.Errorf("%w: catalog %q is not loaded", ErrInvalidAttestation, attestation.CatalogID)
+	}
+	target := mapping.Target{CatalogID: attestation.CatalogID, RequirementID: attestation.RequirementID}
+	if ok {
+		control, requirement, found := catalog.Requirement(attestation.RequirementID)
+		if !found {
+			return nil, fmt.Errorf("%w: requirement %q not found in catalog %q", ErrInvalidAttestation, attestation.RequirementID, attestation.CatalogID)
+		}
+		target.ControlID, target.RequirementID = control.ID, requirement.ID
+	}
+
+	method := layer4.AssessmentMethod{
+		Name:        MethodManual,
+		Run:         true,
+		Result:      &layer4.AssessmentResult{},
+		Description: manualDescription(attestation),
+	}
+	setStatus(&method.Result.Status, attestation.Status)
+	manual := attestation
+	claim := &ConformanceClaim{
+		ClaimID:        uuid.New().String(),
+		Timestamp:      time.Now(),
+		ResourceRef:    attestation.ResourceRef,
+		RawEvidenceRef: evidenceRef,
+		Tenant:         evidence.TenantName(attestation.Tenant),
+		Summary: fmt.Sprintf("%s attested that resource '%s' is %s against requirement %s.",
+			attestation.Attester, attestation.ResourceRef, attestation.Status, target.RequirementID),
+		CatalogID:      target.CatalogID,
+		ControlID:      target.ControlID,
+		ConfigRevision: ruleset.Revision,
+		Assessment: layer4.Assessment{
+			RequirementID: target.RequirementID,
+			Methods:       []layer4.AssessmentMethod{method},
+		},
+		Manual: &manual,
+		Provenance: &Provenance{
+			Mapping:        "manual attestation",
+			CatalogVersion: catalog.Metadata.Version,
+			AgentVersion:   version.Get(),
+		},
+	}
+	return claim, nil
+}
+
+func manualDescription(attestation ManualAttestation) string {
+	description := fmt.Sprintf("%s attested %s, valid until %s: %s.",
+		attestation.Attester, attestation.Status, attestation.ValidUntil.Format(time.RFC3339), strings.TrimSuffix(attestation.Statement, "."))
+	if len(attestation.Artifacts) > 0 {
+		names := make([]string, 0, len(attestation.Artifacts))
+		for _, artifact := range attestation.Artifacts {
+			names = append(names, fmt.Sprintf("%s (%s)", artifact.Name, artifact.URI))
+		}
+		description += " Artifacts: " + strings.Join(names, ", ") + "."
+	}
+	return description
+}
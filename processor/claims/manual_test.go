@@ -0,0 +1,53 @@
+package claims
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManualAttestationValidate(t *testing.T) {
+	valid := ManualAttestation{
+		CatalogID:     "TEST-CAT",
+		RequirementID: "CAT.T01.TR01",
+		ResourceRef:   "pod-a",
+		Status:        StatusCompliant,
+		Statement:     "Reviewed the procedure.",
+		Attester:      Attester{Name: "Jane Doe"},
+		ValidFrom:     testTime.Add(-time.Hour),
+		ValidUntil:    testTime.Add(time.Hour),
+	}
+	with := func(edit func(*ManualAttestation)) ManualAttestation {
+		attestation := valid
+		edit(&attestation)
+		return attestation
+	}
+	tests := []struct {
+		name        string
+		attestation ManualAttestation
+		wantErr     string
+	}{
+		{name: "valid", attestation: valid},
+		{name: "missing target", attestation: with(func(a *ManualAttestation) { a.ResourceRef = "" }), wantErr: "resourceRef are required"},
+		{name: "automated status", attestation: with(func(a *ManualAttestation) { a.Status = StatusStale }), wantErr: "status must be one of"},
+		{name: "missing statement", attestation: with(func(a *ManualAttestation) { a.Statement = "" }), wantErr: "statement is required"},
+		{name: "incomplete artifact", attestation: with(func(a *ManualAttestation) { a.Artifacts = []Artifact{{Name: "review"}} }), wantErr: "artifacts require a name and uri"},
+		{name: "inverted period", attestation: with(func(a *ManualAttestation) { a.ValidUntil = a.ValidFrom }), wantErr: "validUntil must be after validFrom"},
+		{name: "not yet valid", attestation: with(func(a *ManualAttestation) { a.ValidFrom = testTime.Add(time.Minute) }), wantErr: "attestation is not valid until 2025-06-01T12:01:00Z"},
+		{name: "expired", attestation: with(func(a *ManualAttestation) { a.ValidUntil = testTime }), wantErr: "attestation expired at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.attestation.Validate(testTime)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
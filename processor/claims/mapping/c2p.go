@@ -0,0 +1,169 @@
+package mapping
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// C2P locates the compliance-to-policy (C2P) files used to generate policies so the
+// agent maps evidence with the same catalogs, plugins, and plans.
+type C2P struct {
+	// PolicyFile binds catalogs to services and the plugins that enforce them.
+	PolicyFile string
+	// ConfigFile configures the C2P plugins.
+	ConfigFile string
+	// EvalDir holds the assessment plans, named <pluginID>-<catalogID>.yml.
+	EvalDir string
+}
+
+// Binding records which plugin enforces a catalog for a service and the evidence
+// source that reports its decisions.
+type Binding struct {
+	CatalogID string
+	Service   string
+	PluginID  string
+	Source    string
+}
+
+// pluginSources maps C2P plugin IDs to the evidence source reporting their decisions.
+var pluginSources = map[string]string{
+	"kyverno":  "Kyverno",
+	"opa":      "OPA",
+	"openscap": "OpenSCAP",
+}
+
+type c2pPolicy struct {
+	Catalogs []struct {
+		ID       string `yaml:"id"`
+		Services []struct {
+			Service  string `yaml:"service"`
+			PluginID string `yaml:"pluginID"`
+		} `yaml:"services"`
+	} `yaml:"catalogs"`
+}
+
+type c2pConfig struct {
+	Plugins map[string]any `yaml:"plugins"`
+}
+
+// serviceBinding is a catalog file bound to a plugin before the catalog ID is known.
+type serviceBinding struct {
+	catalogFile string
+	service     string
+	pluginID    string
+}
+
+// expand reads the C2P files and adds the catalogs and plans they reference to the paths.
+// The returned contents include the C2P files so changes to them produce a new revision.
+func (c *C2P) expand(paths Paths, contents map[string][]byte) (Paths, []serviceBinding, error) {
+	policyData, err := os.ReadFile(c.PolicyFile)
+	if err != nil {
+		return paths, nil, fmt.Errorf("error reading C2P policy %s: %w", c.PolicyFile, err)
+	}
+	contents[c.PolicyFile] = policyData
+	configData, err := os.ReadFile(c.ConfigFile)
+	if err != nil {
+		return paths, nil, fmt.Errorf("error reading C2P config %s: %w", c.ConfigFile, err)
+	}
+	contents[c.ConfigFile] = configData
+
+	var policy c2pPolicy
+	if err := yaml.Unmarshal(policyData, &policy); err != nil {
+		return paths, nil, fmt.Errorf("error parsing C2P policy %s: %w", c.PolicyFile, err)
+	}
+	var config c2pConfig
+	if err := yaml.Unmarshal(configData, &config); err != nil {
+		return paths, nil, fmt.Errorf("error parsing C2P config %s: %w", c.ConfigFile, err)
+	}
+
+	expanded := paths
+	expanded.PlanSources = make(map[string]string, len(paths.PlanSources))
+	for file, source := range paths.PlanSources {
+		expanded.PlanSources[file] = source
+	}
+
+	var bindings []serviceBinding
+	plugins := make(map[string]struct{})
+	for _, catalog := range policy.Catalogs {
+		// Relative catalog paths are resolved from the working directory, as c2pcli
+		// resolves them.
+		catalogFile := filepath.Clean(catalog.ID)
+		if !contains(expanded.Catalogs, catalogFile) {
+			expanded.Catalogs = append(expanded.Catalogs, catalogFile)
+		}
+		for _, service := range catalog.Services {
+			if _, ok := config.Plugins[service.PluginID]; !ok {
+				return paths, nil, fmt.Errorf("plugin %q for service %q is not configured in %s", service.PluginID, service.Service, c.ConfigFile)
+			}
+			if _, ok := pluginSources[service.PluginID]; !ok {
+				return paths, nil, fmt.Errorf("no evidence source is known for plugin %q", service.PluginID)
+			}
+			bindings = append(bindings, serviceBinding{
+				catalogFile: catalogFile,
+				service:     service.Service,
+				pluginID:    service.PluginID,
+			})
+			plugins[service.PluginID] = struct{}{}
+		}
+	}
+
+	for pluginID := range plugins {
+		planFiles, err := filepath.Glob(filepath.Join(c.EvalDir, pluginID+"-*.y*ml"))
+		if err != nil {
+			return paths, nil, err
+		}
+		sort.Strings(planFiles)
+		for _, planFile := range planFiles {
+			if !contains(expanded.Plans, planFile) {
+				expanded.Plans = append(expanded.Plans, planFile)
+			}
+			expanded.PlanSources[planFile] = pluginSources[pluginID]
+		}
+	}
+	return expanded, bindings, nil
+}
+
+// resolveBindings assigns catalog IDs to service bindings and checks that each
+// bound plugin has a plan for the catalog it enforces.
+func resolveBindings(ruleset *Ruleset, paths Paths, catalogIDs map[string]string, pending []serviceBinding) error {
+	for _, pb := range pending {
+		catalogID, ok := catalogIDs[pb.catalogFile]
+		if !ok {
+			return fmt.Errorf("catalog %s for service %q was not loaded", pb.catalogFile, pb.service)
+		}
+		binding := Binding{
+			CatalogID: catalogID,
+			Service:   pb.service,
+			PluginID:  pb.pluginID,
+			Source:    pluginSources[pb.pluginID],
+		}
+		if !hasPlan(ruleset.Plans, binding) {
+			return fmt.Errorf("no plan for plugin %q and catalog %q in %s", binding.PluginID, binding.CatalogID, paths.C2P.EvalDir)
+		}
+		ruleset.Bindings = append(ruleset.Bindings, binding)
+	}
+	return nil
+}
+
+func hasPlan(plans []Plan, binding Binding) bool {
+	for _, plan := range plans {
+		if plan.CatalogID == binding.CatalogID && strings.EqualFold(plan.Source, binding.Source) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, item string) bool {
+	for _, existing := range items {
+		if filepath.Clean(existing) == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package mapping
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cadence declares how often evidence is expected for a requirement or method. Claims
+// older than the cadence are reported as stale until newer evidence arrives.
+type Cadence struct {
+	CatalogID string `yaml:"catalogId"`
+	// RequirementID limits the cadence to one requirement. Empty applies to every
+	// requirement in the catalog.
+	RequirementID string `yaml:"requirementId,omitempty"`
+	// Method limits the cadence to one evidence source, e.g. OpenSCAP. Empty applies
+	// to every method.
+	Method string        `yaml:"method,omitempty"`
+	Every  time.Duration `yaml:"every"`
+}
+
+func (c Cadence) matches(catalogID, requirementID, method string) bool {
+	return c.CatalogID == catalogID &&
+		(c.RequirementID == "" || strings.EqualFold(c.RequirementID, requirementID)) &&
+		(c.Method == "" || strings.EqualFold(c.Method, method))
+}
+
+// specificity ranks cadences so a requirement and method cadence overrides a requirement
+// cadence, which overrides a method cadence, which overrides a catalog cadence.
+func (c Cadence) specificity() int {
+	score := 0
+	if c.RequirementID != "" {
+		score += 2
+	}
+	if c.Method != "" {
+		score++
+	}
+	return score
+}
+
+// Cadence returns the expected evidence interval for a method assessing a requirement.
+// The most specific matching cadence wins.
+func (r *Ruleset) Cadence(catalogID, requirementID, method string) (time.Duration, bool) {
+	var best *Cadence
+	for i, cadence := range r.Cadences {
+		if !cadence.matches(catalogID, requirementID, method) {
+			continue
+		}
+		if best == nil || cadence.specificity() > best.specificity() {
+			best = &r.Cadences[i]
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	return best.Every, true
+}
+
+func (r *Ruleset) validateCadence(cadence Cadence) error {
+	if cadence.Every <= 0 {
+		return fmt.Errorf("cadence for catalog %q: every must be a positive duration", cadence.CatalogID)
+	}
+	if cadence.RequirementID == "" {
+		if _, ok := r.Catalogs[cadence.CatalogID]; !ok {
+			return fmt.Errorf("cadence: catalog %q is not loaded", cadence.CatalogID)
+		}
+		return nil
+	}
+	if err := r.validateTarget(Target{CatalogID: cadence.CatalogID, RequirementID: cadence.RequirementID}); err != nil {
+		return fmt.Errorf("cadence: %w", err)
+	}
+	return nil
+}
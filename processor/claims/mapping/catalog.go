@@ -0,0 +1,50 @@
+package mapping
+
+import "strings"
+
+// Catalog is the subset of a Layer 2 control catalog needed to resolve and validate mappings.
+type Catalog struct {
+	Metadata        CatalogMetadata `yaml:"metadata"`
+	ControlFamilies []ControlFamily `yaml:"control-families"`
+}
+
+// CatalogMetadata identifies a catalog.
+type CatalogMetadata struct {
+	ID      string `yaml:"id"`
+	Title   string `yaml:"title"`
+	Version string `yaml:"version"`
+}
+
+// ControlFamily groups related controls.
+type ControlFamily struct {
+	Title    string    `yaml:"title"`
+	Controls []Control `yaml:"controls"`
+}
+
+// Control is a catalog control and its assessment requirements.
+type Control struct {
+	ID                     string                  `yaml:"id"`
+	Title                  string                  `yaml:"title"`
+	AssessmentRequirements []AssessmentRequirement `yaml:"assessment-requirements"`
+}
+
+// AssessmentRequirement is a verifiable condition of a control.
+type AssessmentRequirement struct {
+	ID   string `yaml:"id"`
+	Text string `yaml:"text"`
+}
+
+// Requirement returns the control that owns the requirement and whether it was found.
+// IDs are compared case-insensitively because OSCAL control IDs are lowercase.
+func (c Catalog) Requirement(requirementID string) (Control, AssessmentRequirement, bool) {
+	for _, family := range c.ControlFamilies {
+		for _, control := range family.Controls {
+			for _, requirement := range control.AssessmentRequirements {
+				if strings.EqualFold(requirement.ID, requirementID) {
+					return control, requirement, true
+				}
+			}
+		}
+	}
+	return Control{}, AssessmentRequirement{}, false
+}
@@ -0,0 +1,105 @@
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Crosswalk maps requirements in a source catalog to requirements in another framework.
+type Crosswalk struct {
+	SourceCatalogID string           `yaml:"sourceCatalogId"`
+	TargetCatalogID string           `yaml:"targetCatalogId"`
+	Mappings        []CrosswalkEntry `yaml:"mappings"`
+	// origin is the revision of the file the crosswalk was loaded from.
+	origin string
+}
+
+// CrosswalkEntry maps a single source requirement to one or more target requirements.
+type CrosswalkEntry struct {
+	Source  string            `yaml:"source"`
+	Targets []CrosswalkTarget `yaml:"targets"`
+}
+
+// CrosswalkTarget is a requirement in the target framework and how closely it corresponds to the source.
+type CrosswalkTarget struct {
+	ControlID     string `yaml:"controlId"`
+	RequirementID string `yaml:"requirementId"`
+	// Strength rates the correspondence from 1 (weak) to 10 (equivalent).
+	Strength int    `yaml:"strength"`
+	Remarks  string `yaml:"remarks,omitempty"`
+}
+
+// Derivation is a requirement in another framework that a native claim also evidences.
+type Derivation struct {
+	Target   Target
+	Strength int
+	// Mapping describes the crosswalk entry that derived the target.
+	Mapping string
+	// MappingRevision is the revision of the crosswalk file.
+	MappingRevision string
+	// CatalogVersion is the version of the target catalog.
+	CatalogVersion string
+}
+
+const (
+	minStrength = 1
+	maxStrength = 10
+)
+
+func (r *Ruleset) validateCrosswalk(crosswalk Crosswalk) error {
+	var errs []error
+	if crosswalk.SourceCatalogID == "" || crosswalk.TargetCatalogID == "" {
+		return errors.New("crosswalk: sourceCatalogId and targetCatalogId are required")
+	}
+	targetCatalog, targetLoaded := r.Catalogs[crosswalk.TargetCatalogID]
+	for _, entry := range crosswalk.Mappings {
+		if err := r.validateTarget(Target{CatalogID: crosswalk.SourceCatalogID, RequirementID: entry.Source}); err != nil {
+			errs = append(errs, fmt.Errorf("crosswalk source: %w", err))
+		}
+		for _, target := range entry.Targets {
+			if target.Strength < minStrength || target.Strength > maxStrength {
+				errs = append(errs, fmt.Errorf("crosswalk %s -> %s: strength %d is not between %d and %d",
+					entry.Source, target.RequirementID, target.Strength, minStrength, maxStrength))
+			}
+			// Target frameworks are often not loaded as catalogs; only check them when they are.
+			if !targetLoaded {
+				continue
+			}
+			if _, _, ok := targetCatalog.Requirement(target.RequirementID); !ok {
+				errs = append(errs, fmt.Errorf("crosswalk target: requirement %q not found in catalog %q",
+					target.RequirementID, crosswalk.TargetCatalogID))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Derive returns the requirements in other frameworks that are mapped from the target.
+func (r *Ruleset) Derive(target Target) []Derivation {
+	var derivations []Derivation
+	for _, crosswalk := range r.Crosswalks {
+		if crosswalk.SourceCatalogID != target.CatalogID {
+			continue
+		}
+		for _, entry := range crosswalk.Mappings {
+			if !strings.EqualFold(entry.Source, target.RequirementID) {
+				continue
+			}
+			for _, mapped := range entry.Targets {
+				derivations = append(derivations, Derivation{
+					Target: r.complete(Target{
+						CatalogID:     crosswalk.TargetCatalogID,
+						ControlID:     mapped.ControlID,
+						RequirementID: mapped.RequirementID,
+					}),
+					Strength:        mapped.Strength,
+					Mapping:         fmt.Sprintf("crosswalk %s/%s", crosswalk.SourceCatalogID, entry.Source),
+					MappingRevision: crosswalk.origin,
+					CatalogVersion:  r.Catalogs[crosswalk.TargetCatalogID].Metadata.Version,
+				})
+			}
+		}
+	}
+	return derivations
+}
@@ -0,0 +1,107 @@
+package mapping
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testTargetCatalog = `metadata:
+  id: TARGET-CAT
+  version: 2.0.0
+control-families:
+  - title: Target Family
+    controls:
+      - id: TGT-1
+        assessment-requirements:
+          - id: TGT-1.a
+`
+
+func crosswalk(target, requirement string, strength int) string {
+	return fmt.Sprintf(`sourceCatalogId: TEST-CAT
+targetCatalogId: %s
+mappings:
+  - source: CAT.T01.TR01
+    targets:
+      - requirementId: %s
+        strength: %d
+`, target, requirement, strength)
+}
+
+func TestLoadValidatesCrosswalks(t *testing.T) {
+	tests := []struct {
+		name      string
+		crosswalk string
+		wantErr   string
+	}{
+		{name: "weakest strength", crosswalk: crosswalk("TARGET-CAT", "TGT-1.a", 1)},
+		{name: "equivalent strength", crosswalk: crosswalk("TARGET-CAT", "TGT-1.a", 10)},
+		{name: "strength too low", crosswalk: crosswalk("TARGET-CAT", "TGT-1.a", 0), wantErr: "strength 0 is not between 1 and 10"},
+		{name: "strength too high", crosswalk: crosswalk("TARGET-CAT", "TGT-1.a", 11), wantErr: "strength 11 is not between 1 and 10"},
+		{name: "unknown target requirement", crosswalk: crosswalk("TARGET-CAT", "TGT-9.z", 5), wantErr: `requirement "TGT-9.z" not found in catalog "TARGET-CAT"`},
+		{name: "unloaded target catalog", crosswalk: crosswalk("NIST-800-53", "CM-7(5)", 5)},
+		{name: "unloaded target still checks strength", crosswalk: crosswalk("NIST-800-53", "CM-7(5)", 12), wantErr: "strength 12"},
+		{name: "unknown source requirement", crosswalk: strings.Replace(crosswalk("TARGET-CAT", "TGT-1.a", 5), "CAT.T01.TR01", "CAT.T01.TR99", 1), wantErr: "crosswalk source"},
+		{name: "missing catalogs", crosswalk: "mappings: []\n", wantErr: "sourceCatalogId and targetCatalogId are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "target.yml": testTargetCatalog, "crosswalk.yaml": tt.crosswalk})
+			_, err := Load(Paths{
+				Catalogs:   []string{files["catalog.yml"], files["target.yml"]},
+				Crosswalks: []string{files["crosswalk.yaml"]},
+			})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRulesetDerive(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"catalog.yml":  testCatalog,
+		"target.yml":   testTargetCatalog,
+		"loaded.yaml":  crosswalk("TARGET-CAT", "tgt-1.a", 9),
+		"unloaded.yml": crosswalk("NIST-800-53", "CM-7(5)", 4),
+	})
+	ruleset, err := Load(Paths{
+		Catalogs:   []string{files["catalog.yml"], files["target.yml"]},
+		Crosswalks: []string{files["loaded.yaml"], files["unloaded.yml"]},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	derivations := ruleset.Derive(Target{CatalogID: "TEST-CAT", ControlID: "CAT.T01", RequirementID: "cat.t01.tr01"})
+	if len(derivations) != 2 {
+		t.Fatalf("got %d derivations, want 2: %+v", len(derivations), derivations)
+	}
+	// Targets in loaded catalogs are completed from the catalog.
+	loaded := derivations[0]
+	if want := (Target{CatalogID: "TARGET-CAT", ControlID: "TGT-1", RequirementID: "TGT-1.a"}); !reflect.DeepEqual(loaded.Target, want) {
+		t.Errorf("target = %+v, want %+v", loaded.Target, want)
+	}
+	if loaded.Strength != 9 || loaded.CatalogVersion != "2.0.0" || loaded.Mapping != "crosswalk TEST-CAT/CAT.T01.TR01" || loaded.MappingRevision == "" {
+		t.Errorf("derivation = %+v", loaded)
+	}
+	// Targets in catalogs that are not loaded are reported as written.
+	unloaded := derivations[1]
+	if want := (Target{CatalogID: "NIST-800-53", RequirementID: "CM-7(5)"}); !reflect.DeepEqual(unloaded.Target, want) {
+		t.Errorf("target = %+v, want %+v", unloaded.Target, want)
+	}
+	if unloaded.Strength != 4 || unloaded.CatalogVersion != "" {
+		t.Errorf("derivation = %+v", unloaded)
+	}
+
+	if got := ruleset.Derive(Target{CatalogID: "TARGET-CAT", RequirementID: "TGT-1.a"}); len(got) != 0 {
+		t.Errorf("crosswalks are not reversible, got %+v", got)
+	}
+}
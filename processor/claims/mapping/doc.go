@@ -0,0 +1,4 @@
+// Package mapping resolves raw evidence to the catalog requirements it is assessed against.
+// Mappings are built from assessment plans, catalogs, explicit mapping rules, and OSCAL
+// component definitions loaded from files.
+package mapping
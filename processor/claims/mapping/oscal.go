@@ -0,0 +1,126 @@
+package mapping
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OSCAL property names used by compliance-to-policy (C2P) to link controls to policy rules and checks.
+const (
+	ruleIDProp             = "Rule_Id"
+	checkIDProp            = "Check_Id"
+	frameworkShortNameProp = "Framework_Short_Name"
+	validationComponent    = "validation"
+)
+
+// componentDefinitionDocument is the subset of an OSCAL component definition needed
+// to build mapping rules. JSON documents are parsed as YAML.
+type componentDefinitionDocument struct {
+	ComponentDefinition struct {
+		Components []component `yaml:"components"`
+	} `yaml:"component-definition"`
+}
+
+type component struct {
+	Type                   string                  `yaml:"type"`
+	Title                  string                  `yaml:"title"`
+	Props                  []property              `yaml:"props"`
+	ControlImplementations []controlImplementation `yaml:"control-implementations"`
+}
+
+type property struct {
+	Name    string `yaml:"name"`
+	Value   string `yaml:"value"`
+	Remarks string `yaml:"remarks"`
+}
+
+type controlImplementation struct {
+	Source                  string                   `yaml:"source"`
+	Props                   []property               `yaml:"props"`
+	ImplementedRequirements []implementedRequirement `yaml:"implemented-requirements"`
+}
+
+type implementedRequirement struct {
+	ControlID string     `yaml:"control-id"`
+	Props     []property `yaml:"props"`
+}
+
+// check is a policy check that implements a rule, reported by the validation component's source.
+type check struct {
+	source   string
+	policyID string
+}
+
+// rulesFromComponentDefinition builds mapping rules from the implemented requirements
+// of a component definition. Rules are linked to the checks declared by validation
+// components in the same rule set; rules without checks map the rule ID directly.
+func rulesFromComponentDefinition(doc componentDefinitionDocument) ([]Rule, error) {
+	checksByRule := make(map[string][]check)
+	for _, comp := range doc.ComponentDefinition.Components {
+		if !strings.EqualFold(comp.Type, validationComponent) {
+			continue
+		}
+		ruleSets := make(map[string]string)
+		for _, prop := range comp.Props {
+			if prop.Name == ruleIDProp {
+				ruleSets[prop.Remarks] = prop.Value
+			}
+		}
+		for _, prop := range comp.Props {
+			if prop.Name != checkIDProp {
+				continue
+			}
+			ruleID, ok := ruleSets[prop.Remarks]
+			if !ok {
+				return nil, fmt.Errorf("check %q in component %q is not part of a rule set", prop.Value, comp.Title)
+			}
+			checksByRule[ruleID] = append(checksByRule[ruleID], check{source: comp.Title, policyID: prop.Value})
+		}
+	}
+
+	var rules []Rule
+	seen := make(map[Rule]struct{})
+	add := func(rule Rule) {
+		if _, ok := seen[rule]; !ok {
+			seen[rule] = struct{}{}
+			rules = append(rules, rule)
+		}
+	}
+	for _, comp := range doc.ComponentDefinition.Components {
+		for _, implementation := range comp.ControlImplementations {
+			catalogID := propValue(implementation.Props, frameworkShortNameProp)
+			if catalogID == "" {
+				catalogID = implementation.Source
+			}
+			for _, requirement := range implementation.ImplementedRequirements {
+				target := Target{
+					CatalogID:     catalogID,
+					RequirementID: requirement.ControlID,
+				}
+				for _, prop := range requirement.Props {
+					if prop.Name != ruleIDProp {
+						continue
+					}
+					checks, ok := checksByRule[prop.Value]
+					if !ok {
+						add(Rule{PolicyID: prop.Value, Target: target})
+						continue
+					}
+					for _, c := range checks {
+						add(Rule{Source: c.source, PolicyID: c.policyID, Target: target})
+					}
+				}
+			}
+		}
+	}
+	return rules, nil
+}
+
+func propValue(props []property, name string) string {
+	for _, prop := range props {
+		if prop.Name == name {
+			return prop.Value
+		}
+	}
+	return ""
+}
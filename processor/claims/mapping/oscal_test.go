@@ -0,0 +1,90 @@
+package mapping
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func readComponentDefinition(t *testing.T, data []byte) componentDefinitionDocument {
+	t.Helper()
+	var doc componentDefinitionDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestRulesFromComponentDefinition(t *testing.T) {
+	data, err := os.ReadFile("testdata/component-definition.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules, err := rulesFromComponentDefinition(readComponentDefinition(t, data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requirement := Target{CatalogID: "TEST-CAT", RequirementID: "CAT.T01.TR01"}
+	want := []Rule{
+		// Rule IDs are replaced by the checks paired with them through their rule set.
+		{Source: "Kyverno", PolicyID: "check-base-images", Target: requirement},
+		{Source: "Kyverno", PolicyID: "disallow-privileged", Target: requirement},
+		{Source: "Kyverno", PolicyID: "restrict-capabilities", Target: requirement},
+		// Validation component types match case-insensitively, with their own rule sets.
+		{Source: "OPA", PolicyID: "deny_privileged", Target: requirement},
+		// Rules without checks map the rule ID from any source.
+		{PolicyID: "manual-review", Target: Target{CatalogID: "OTHER-CAT", RequirementID: "OTH.01"}},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("rules =\n%+v\nwant\n%+v", rules, want)
+	}
+}
+
+func TestRulesFromComponentDefinitionRejectsMalformedProps(t *testing.T) {
+	tests := []struct {
+		name    string
+		props   string
+		wantErr string
+	}{
+		{
+			name:    "check without rule set",
+			props:   "- {name: Check_Id, value: check-1, remarks: rule_set_9}\n",
+			wantErr: `check "check-1" in component "Kyverno" is not part of a rule set`,
+		},
+		{
+			name:    "check without remarks",
+			props:   "- {name: Rule_Id, value: rule-1, remarks: rule_set_0}\n- {name: Check_Id, value: check-1}\n",
+			wantErr: `check "check-1" in component "Kyverno" is not part of a rule set`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			props := strings.ReplaceAll(strings.TrimSpace(tt.props), "\n", "\n        ")
+			doc := "component-definition:\n  components:\n    - type: validation\n      title: Kyverno\n      props:\n        " + props + "\n"
+			_, err := rulesFromComponentDefinition(readComponentDefinition(t, []byte(doc)))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadImportsComponentDefinitions(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"catalog.yml": testCatalog,
+		"broken.yaml": "component-definition: [",
+	})
+	paths := Paths{Catalogs: []string{files["catalog.yml"]}, ComponentDefinitions: []string{files["broken.yaml"]}}
+	if _, err := Load(paths); err == nil || !strings.Contains(err.Error(), "error parsing component definition") {
+		t.Errorf("malformed component definition: %v", err)
+	}
+
+	// Component definitions that reference unloaded catalogs fail validation.
+	paths.ComponentDefinitions = []string{"testdata/component-definition.yaml"}
+	if _, err := Load(paths); err == nil || !strings.Contains(err.Error(), "OTHER-CAT") {
+		t.Errorf("unloaded catalog: %v", err)
+	}
+}
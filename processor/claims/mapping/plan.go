@@ -0,0 +1,33 @@
+package mapping
+
+// Plan is a pre-run Layer 4 evaluation plan listing the methods that assess each requirement.
+type Plan struct {
+	CatalogID   string              `yaml:"catalog_id"`
+	Evaluations []ControlEvaluation `yaml:"evaluations"`
+	// Source is the evidence source the plan methods report through. An empty
+	// source matches evidence from any source.
+	Source string `yaml:"-"`
+	// Tenant is the only tenant whose evidence the plan maps. An empty tenant matches
+	// evidence from any tenant.
+	Tenant string `yaml:"-"`
+	// origin is the revision of the file the plan was loaded from.
+	origin string
+}
+
+// ControlEvaluation lists the assessments planned for a control.
+type ControlEvaluation struct {
+	ControlID   string       `yaml:"control_id"`
+	Assessments []Assessment `yaml:"assessments"`
+}
+
+// Assessment lists the methods planned for a requirement.
+type Assessment struct {
+	RequirementID string   `yaml:"requirement_id"`
+	Methods       []Method `yaml:"methods"`
+}
+
+// Method is a planned assessment method. The method name is the policy ID
+// reported by the evidence source.
+type Method struct {
+	Name string `yaml:"name"`
+}
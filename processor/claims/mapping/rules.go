@@ -0,0 +1,47 @@
+package mapping
+
+import (
+	"strings"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// Rules is a mapping rules document.
+type Rules struct {
+	// Default is the target used when no plan or rule matches the evidence.
+	Default *Target `yaml:"default,omitempty"`
+	Rules   []Rule  `yaml:"rules"`
+	// Cadences declare how often evidence is expected.
+	Cadences []Cadence `yaml:"cadences,omitempty"`
+	// Waivers accept the risk of failing requirements until they expire.
+	Waivers []Waiver `yaml:"waivers,omitempty"`
+}
+
+// Rule maps a policy reported by an evidence source to a requirement.
+type Rule struct {
+	// Source is the evidence source, e.g. Kyverno. An empty source matches any source.
+	Source   string `yaml:"source,omitempty"`
+	PolicyID string `yaml:"policyId"`
+	// Tenant limits the rule to evidence from one tenant. An empty tenant matches any tenant.
+	Tenant string `yaml:"tenant,omitempty"`
+	Target `yaml:",inline"`
+	// origin is the revision of the file the rule was loaded from.
+	origin string
+}
+
+// Target identifies the requirement evidence is assessed against.
+type Target struct {
+	CatalogID     string `yaml:"catalogId"`
+	ControlID     string `yaml:"controlId"`
+	RequirementID string `yaml:"requirementId"`
+}
+
+func (r Rule) matches(source, policyID, tenant string) bool {
+	if r.PolicyID != policyID {
+		return false
+	}
+	if r.Tenant != "" && r.Tenant != evidence.TenantName(tenant) {
+		return false
+	}
+	return r.Source == "" || strings.EqualFold(r.Source, source)
+}
@@ -0,0 +1,427 @@
+package mapping
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// BuiltinRevision is the revision of the ruleset used when no files are configured.
+const BuiltinRevision = "builtin"
+
+// Paths lists the files a Ruleset is loaded from.
+type Paths struct {
+	Catalogs []string
+	Plans    []string
+	Rules    []string
+	// ComponentDefinitions are OSCAL component definitions whose implemented
+	// requirements link controls to policy rules and checks.
+	ComponentDefinitions []string
+	// Crosswalks map requirements between catalogs to derive claims in other frameworks.
+	Crosswalks []string
+	// PlanSources restricts the methods of a plan file to a single evidence source.
+	PlanSources map[string]string
+	// PlanTenants restricts a plan file to evidence from a single tenant, so each team
+	// or cluster can assess its evidence against its own plan.
+	PlanTenants map[string]string
+	// C2P derives catalogs, plans, and plan sources from C2P policy generation files.
+	C2P *C2P
+}
+
+// Empty reports whether no files are configured.
+func (p Paths) Empty() bool {
+	return len(p.Catalogs) == 0 && len(p.Plans) == 0 && len(p.Rules) == 0 &&
+		len(p.ComponentDefinitions) == 0 && len(p.Crosswalks) == 0 && p.C2P == nil
+}
+
+func (p Paths) all() []string {
+	var all []string
+	all = append(all, p.Catalogs...)
+	all = append(all, p.Plans...)
+	all = append(all, p.Rules...)
+	all = append(all, p.ComponentDefinitions...)
+	all = append(all, p.Crosswalks...)
+	return all
+}
+
+// Ruleset is an immutable, validated set of catalogs, plans, and mapping rules.
+type Ruleset struct {
+	// Revision identifies the content the ruleset was loaded from.
+	Revision string
+	Catalogs map[string]Catalog
+	Plans    []Plan
+	Rules    []Rule
+	// Bindings lists the plugins enforcing each catalog when loaded from C2P files.
+	Bindings   []Binding
+	Crosswalks []Crosswalk
+	Cadences   []Cadence
+	Waivers    []Waiver
+	fallback   *Target
+	// fallbackOrigin is the revision of the rules file declaring the default target.
+	fallbackOrigin string
+}
+
+// Default returns the ruleset used when no files are configured.
+// It maps all evidence to a single example requirement.
+func Default() *Ruleset {
+	return &Ruleset{
+		Revision: BuiltinRevision,
+		Catalogs: make(map[string]Catalog),
+		fallback: &Target{
+			CatalogID:     "EXMP-10001",
+			ControlID:     "CTRL-1",
+			RequirementID: "CTRL-1.1",
+		},
+	}
+}
+
+// Load reads and validates the ruleset from the given files.
+func Load(paths Paths) (*Ruleset, error) {
+	if paths.Empty() {
+		return Default(), nil
+	}
+	loaded, err := read(paths)
+	if err != nil {
+		return nil, err
+	}
+	return parse(loaded)
+}
+
+// loadedFiles holds the raw contents of a ruleset before parsing.
+type loadedFiles struct {
+	paths    Paths
+	contents map[string][]byte
+	bindings []serviceBinding
+	revision string
+	// fileRevisions are the revisions of each file, recorded in claim provenance.
+	fileRevisions map[string]string
+}
+
+// read returns the contents of every configured file and a revision computed over all of them.
+func read(paths Paths) (loadedFiles, error) {
+	loaded := loadedFiles{
+		paths:         paths,
+		contents:      make(map[string][]byte),
+		fileRevisions: make(map[string]string),
+	}
+	if paths.C2P != nil {
+		var err error
+		loaded.paths, loaded.bindings, err = paths.C2P.expand(paths, loaded.contents)
+		if err != nil {
+			return loaded, err
+		}
+	}
+
+	for _, file := range loaded.paths.all() {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return loaded, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		loaded.contents[file] = data
+	}
+
+	files := make([]string, 0, len(loaded.contents))
+	for file := range loaded.contents {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	hash := sha256.New()
+	for _, file := range files {
+		fileHash := sha256.Sum256(loaded.contents[file])
+		fmt.Fprintf(hash, "%s %x\n", file, fileHash)
+		loaded.fileRevisions[file] = hex.EncodeToString(fileHash[:])[:12]
+	}
+	loaded.revision = hex.EncodeToString(hash.Sum(nil))[:12]
+	return loaded, nil
+}
+
+func parse(loaded loadedFiles) (*Ruleset, error) {
+	paths, contents, revision := loaded.paths, loaded.contents, loaded.revision
+	ruleset := &Ruleset{
+		Revision: revision,
+		Catalogs: make(map[string]Catalog),
+	}
+	catalogIDs := make(map[string]string, len(paths.Catalogs))
+	for _, file := range paths.Catalogs {
+		var catalog Catalog
+		if err := yaml.Unmarshal(contents[file], &catalog); err != nil {
+			return nil, fmt.Errorf("error parsing catalog %s: %w", file, err)
+		}
+		if catalog.Metadata.ID == "" {
+			return nil, fmt.Errorf("catalog %s has no metadata id", file)
+		}
+		ruleset.Catalogs[catalog.Metadata.ID] = catalog
+		catalogIDs[filepath.Clean(file)] = catalog.Metadata.ID
+	}
+	for _, file := range paths.Plans {
+		var plan Plan
+		if err := yaml.Unmarshal(contents[file], &plan); err != nil {
+			return nil, fmt.Errorf("error parsing plan %s: %w", file, err)
+		}
+		plan.Source = paths.PlanSources[file]
+		plan.Tenant = paths.PlanTenants[file]
+		plan.origin = loaded.fileRevisions[file]
+		ruleset.Plans = append(ruleset.Plans, plan)
+	}
+	for _, file := range paths.Rules {
+		var rules Rules
+		if err := yaml.Unmarshal(contents[file], &rules); err != nil {
+			return nil, fmt.Errorf("error parsing mapping rules %s: %w", file, err)
+		}
+		for _, rule := range rules.Rules {
+			rule.origin = loaded.fileRevisions[file]
+			ruleset.Rules = append(ruleset.Rules, rule)
+		}
+		ruleset.Cadences = append(ruleset.Cadences, rules.Cadences...)
+		ruleset.Waivers = append(ruleset.Waivers, rules.Waivers...)
+		if rules.Default != nil {
+			ruleset.fallback = rules.Default
+			ruleset.fallbackOrigin = loaded.fileRevisions[file]
+		}
+	}
+	// Imported rules are appended after explicit rules so hand-written overrides win.
+	for _, file := range paths.ComponentDefinitions {
+		var doc componentDefinitionDocument
+		if err := yaml.Unmarshal(contents[file], &doc); err != nil {
+			return nil, fmt.Errorf("error parsing component definition %s: %w", file, err)
+		}
+		rules, err := rulesFromComponentDefinition(doc)
+		if err != nil {
+			return nil, fmt.Errorf("error importing component definition %s: %w", file, err)
+		}
+		for _, rule := range rules {
+			rule.origin = loaded.fileRevisions[file]
+			ruleset.Rules = append(ruleset.Rules, rule)
+		}
+	}
+	for _, file := range paths.Crosswalks {
+		var crosswalk Crosswalk
+		if err := yaml.Unmarshal(contents[file], &crosswalk); err != nil {
+			return nil, fmt.Errorf("error parsing crosswalk %s: %w", file, err)
+		}
+		crosswalk.origin = loaded.fileRevisions[file]
+		ruleset.Crosswalks = append(ruleset.Crosswalks, crosswalk)
+	}
+	if err := resolveBindings(ruleset, paths, catalogIDs, loaded.bindings); err != nil {
+		return nil, fmt.Errorf("invalid ruleset %s: %w", revision, err)
+	}
+	if err := ruleset.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid ruleset %s: %w", revision, err)
+	}
+	return ruleset, nil
+}
+
+// Validate checks that every plan, rule, default target, crosswalk source, cadence, and waiver references a requirement in a loaded catalog.
+// The builtin default target is exempt since the builtin ruleset has no catalogs.
+func (r *Ruleset) Validate() error {
+	var errs []error
+	if r.fallback != nil && r.fallbackOrigin != "" {
+		if err := r.validateTarget(*r.fallback); err != nil {
+			errs = append(errs, fmt.Errorf("default: %w", err))
+		}
+	}
+	for _, plan := range r.Plans {
+		for _, evaluation := range plan.Evaluations {
+			for _, assessment := range evaluation.Assessments {
+				target := Target{
+					CatalogID:     plan.CatalogID,
+					ControlID:     evaluation.ControlID,
+					RequirementID: assessment.RequirementID,
+				}
+				if err := r.validateTarget(target); err != nil {
+					errs = append(errs, fmt.Errorf("plan: %w", err))
+				}
+			}
+		}
+	}
+	for _, rule := range r.Rules {
+		if rule.PolicyID == "" {
+			errs = append(errs, errors.New("rule: policyId is required"))
+			continue
+		}
+		if err := r.validateTarget(rule.Target); err != nil {
+			errs = append(errs, fmt.Errorf("rule for policy %s: %w", rule.PolicyID, err))
+		}
+	}
+	for _, crosswalk := range r.Crosswalks {
+		if err := r.validateCrosswalk(crosswalk); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, cadence := range r.Cadences {
+		if err := r.validateCadence(cadence); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	waiverIDs := make(map[string]bool, len(r.Waivers))
+	for _, waiver := range r.Waivers {
+		if waiverIDs[waiver.ID] {
+			errs = append(errs, fmt.Errorf("waiver %s: duplicate id", waiver.ID))
+		}
+		waiverIDs[waiver.ID] = true
+		if err := r.validateWaiver(waiver); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Ruleset) validateTarget(target Target) error {
+	catalog, ok := r.Catalogs[target.CatalogID]
+	if !ok {
+		return fmt.Errorf("catalog %q is not loaded", target.CatalogID)
+	}
+	control, _, ok := catalog.Requirement(target.RequirementID)
+	if !ok {
+		return fmt.Errorf("requirement %q not found in catalog %q", target.RequirementID, target.CatalogID)
+	}
+	if target.ControlID != "" && !strings.EqualFold(control.ID, target.ControlID) {
+		return fmt.Errorf("requirement %q belongs to control %q, not %q", target.RequirementID, control.ID, target.ControlID)
+	}
+	return nil
+}
+
+// Resolve returns the requirement the evidence is assessed against.
+// Explicit rules take precedence over plan methods, followed by the default target.
+func (r *Ruleset) Resolve(rawEv evidence.RawEvidence) (Target, error) {
+	resolution, err := r.Explain(rawEv)
+	return resolution.Target, err
+}
+
+// Resolution is the requirement evidence resolves to and the mapping that selected it.
+type Resolution struct {
+	Target
+	// Mapping describes the rule, plan, or default target that matched.
+	Mapping string
+	// MappingRevision is the revision of the file the mapping was loaded from.
+	MappingRevision string
+	// CatalogVersion is the version of the catalog declaring the requirement.
+	CatalogVersion string
+}
+
+// Explain resolves the evidence like Resolve and reports which mapping matched.
+// Rules and plans for another tenant are skipped.
+func (r *Ruleset) Explain(rawEv evidence.RawEvidence) (Resolution, error) {
+	for _, rule := range r.Rules {
+		if rule.matches(rawEv.Source, rawEv.PolicyID, rawEv.Tenant) {
+			return r.resolution(r.complete(rule.Target), fmt.Sprintf("rule %s/%s%s", orAny(rule.Source), rule.PolicyID, forTenant(rule.Tenant)), rule.origin), nil
+		}
+	}
+	for _, plan := range r.Plans {
+		if plan.Source != "" && !strings.EqualFold(plan.Source, rawEv.Source) {
+			continue
+		}
+		if plan.Tenant != "" && plan.Tenant != evidence.TenantName(rawEv.Tenant) {
+			continue
+		}
+		for _, evaluation := range plan.Evaluations {
+			for _, assessment := range evaluation.Assessments {
+				for _, method := range assessment.Methods {
+					if method.Name == rawEv.PolicyID {
+						target := Target{
+							CatalogID:     plan.CatalogID,
+							ControlID:     evaluation.ControlID,
+							RequirementID: assessment.RequirementID,
+						}
+						return r.resolution(r.complete(target), fmt.Sprintf("plan %s/%s%s", plan.CatalogID, method.Name, forTenant(plan.Tenant)), plan.origin), nil
+					}
+				}
+			}
+		}
+	}
+	if r.fallback != nil {
+		origin := r.fallbackOrigin
+		if origin == "" {
+			origin = BuiltinRevision
+		}
+		return r.resolution(r.complete(*r.fallback), "default", origin), nil
+	}
+	return Resolution{}, fmt.Errorf("no mapping for policy %q from source %s for tenant %s in ruleset %s",
+		rawEv.PolicyID, rawEv.Source, evidence.TenantName(rawEv.Tenant), r.Revision)
+}
+
+func (r *Ruleset) resolution(target Target, mapping, origin string) Resolution {
+	return Resolution{
+		Target:          target,
+		Mapping:         mapping,
+		MappingRevision: origin,
+		CatalogVersion:  r.Catalogs[target.CatalogID].Metadata.Version,
+	}
+}
+
+func orAny(source string) string {
+	if source == "" {
+		return "*"
+	}
+	return source
+}
+
+func forTenant(tenant string) string {
+	if tenant == "" {
+		return ""
+	}
+	return " for tenant " + tenant
+}
+
+// complete fills in the control ID from the catalog when a rule or default target
+// omits it and uses the requirement ID as written in the catalog.
+func (r *Ruleset) complete(target Target) Target {
+	if catalog, ok := r.Catalogs[target.CatalogID]; ok {
+		if control, requirement, ok := catalog.Requirement(target.RequirementID); ok {
+			target.RequirementID = requirement.ID
+			if target.ControlID == "" {
+				target.ControlID = control.ID
+			}
+		}
+	}
+	return target
+}
+
+// MappedMethod is a policy that evidences a requirement.
+type MappedMethod struct {
+	// Source is the evidence source reporting the policy. Empty means any source.
+	Source   string `json:"source,omitempty"`
+	PolicyID string `json:"policyId"`
+}
+
+// Methods returns every policy mapped to the requirement by rules or plans.
+func (r *Ruleset) Methods(catalogID, requirementID string) []MappedMethod {
+	methods := []MappedMethod{}
+	seen := make(map[MappedMethod]struct{})
+	add := func(method MappedMethod) {
+		if _, ok := seen[method]; !ok {
+			seen[method] = struct{}{}
+			methods = append(methods, method)
+		}
+	}
+	for _, rule := range r.Rules {
+		if rule.CatalogID == catalogID && strings.EqualFold(rule.RequirementID, requirementID) {
+			add(MappedMethod{Source: rule.Source, PolicyID: rule.PolicyID})
+		}
+	}
+	for _, plan := range r.Plans {
+		if plan.CatalogID != catalogID {
+			continue
+		}
+		for _, evaluation := range plan.Evaluations {
+			for _, assessment := range evaluation.Assessments {
+				if !strings.EqualFold(assessment.RequirementID, requirementID) {
+					continue
+				}
+				for _, method := range assessment.Methods {
+					add(MappedMethod{Source: plan.Source, PolicyID: method.Name})
+				}
+			}
+		}
+	}
+	return methods
+}
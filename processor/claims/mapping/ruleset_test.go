@@ -0,0 +1,181 @@
+package mapping
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+const testCatalog = `metadata:
+  id: TEST-CAT
+  version: 0.1.0
+control-families:
+  - title: Example Family
+    controls:
+      - id: CAT.T01
+        assessment-requirements:
+          - id: CAT.T01.TR01
+`
+
+const testPlan = `catalog_id: TEST-CAT
+evaluations:
+- control_id: CAT.T01
+  assessments:
+  - requirement_id: cat.t01.tr01
+    methods:
+      - name: allowed-base-images
+`
+
+// writeFiles writes the files under a temporary directory and returns their paths by name.
+func writeFiles(t *testing.T, files map[string]string) map[string]string {
+	t.Helper()
+	dir := t.TempDir()
+	paths := make(map[string]string, len(files))
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths[name] = path
+	}
+	return paths
+}
+
+func TestLoadValidatesDefaultTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{
+			name:  "known requirement",
+			rules: "default:\n  catalogId: TEST-CAT\n  requirementId: CAT.T01.TR01\n",
+		},
+		{
+			name:    "unknown requirement",
+			rules:   "default:\n  catalogId: TEST-CAT\n  requirementId: CAT.T01.TR99\n",
+			wantErr: `default: requirement "CAT.T01.TR99" not found`,
+		},
+		{
+			name:    "unknown catalog",
+			rules:   "default:\n  catalogId: OTHER\n  requirementId: CAT.T01.TR01\n",
+			wantErr: `default: catalog "OTHER" is not loaded`,
+		},
+		{
+			name:    "wrong control",
+			rules:   "default:\n  catalogId: TEST-CAT\n  controlId: CAT.T02\n  requirementId: CAT.T01.TR01\n",
+			wantErr: `belongs to control "CAT.T01"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "rules.yaml": tt.rules})
+			_, err := Load(Paths{Catalogs: []string{files["catalog.yml"]}, Rules: []string{files["rules.yaml"]}})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExplainCanonicalizesTargets(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    bool
+		rules   string
+		policy  string
+		mapping string
+	}{
+		{name: "rule", rules: "rules:\n  - policyId: p1\n    catalogId: TEST-CAT\n    requirementId: cat.t01.tr01\n", policy: "p1", mapping: "rule */p1"},
+		{name: "plan", plan: true, policy: "allowed-base-images", mapping: "plan TEST-CAT/allowed-base-images"},
+		{name: "default", rules: "default:\n  catalogId: TEST-CAT\n  requirementId: cat.t01.tr01\n", policy: "unmapped", mapping: "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "plan.yml": testPlan, "rules.yaml": tt.rules})
+			paths := Paths{Catalogs: []string{files["catalog.yml"]}}
+			if tt.plan {
+				paths.Plans = []string{files["plan.yml"]}
+			}
+			if tt.rules != "" {
+				paths.Rules = []string{files["rules.yaml"]}
+			}
+			ruleset, err := Load(paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resolution, err := ruleset.Explain(evidence.RawEvidence{Metadata: evidence.Metadata{Source: "Kyverno", PolicyID: tt.policy}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolution.RequirementID != "CAT.T01.TR01" || resolution.ControlID != "CAT.T01" {
+				t.Errorf("target = %s/%s, want CAT.T01/CAT.T01.TR01", resolution.ControlID, resolution.RequirementID)
+			}
+			if resolution.Mapping != tt.mapping {
+				t.Errorf("mapping = %q, want %q", resolution.Mapping, tt.mapping)
+			}
+			if resolution.CatalogVersion != "0.1.0" {
+				t.Errorf("catalog version = %q, want 0.1.0", resolution.CatalogVersion)
+			}
+		})
+	}
+}
+
+func TestC2PResolvesCatalogsFromWorkingDirectory(t *testing.T) {
+	files := writeFiles(t, map[string]string{
+		"c2p/policy.yaml":                "catalogs:\n  - id: ./baselines/baseline.yml\n    services:\n      - service: kubernetes\n        pluginID: kyverno\n",
+		"c2p/config.yaml":                "plugins:\n  kyverno:\n    policy-dir: policies\n    retries: 3\n    enabled: true\n",
+		"baselines/baseline.yml":         testCatalog,
+		"c2p/evals/kyverno-TEST-CAT.yml": testPlan,
+	})
+	t.Chdir(filepath.Dir(filepath.Dir(files["baselines/baseline.yml"])))
+	ruleset, err := Load(Paths{C2P: &C2P{
+		PolicyFile: "c2p/policy.yaml",
+		ConfigFile: "c2p/config.yaml",
+		EvalDir:    "c2p/evals",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ruleset.Catalogs["TEST-CAT"]; !ok {
+		t.Fatal("catalog TEST-CAT was not loaded")
+	}
+	want := Binding{CatalogID: "TEST-CAT", Service: "kubernetes", PluginID: "kyverno", Source: "Kyverno"}
+	if len(ruleset.Bindings) != 1 || ruleset.Bindings[0] != want {
+		t.Errorf("bindings = %+v, want [%+v]", ruleset.Bindings, want)
+	}
+}
+
+// TestC2PLoadsRepositoryFiles loads the C2P files CI generates policies from, with the
+// same working directory and flags as the README.
+func TestC2PLoadsRepositoryFiles(t *testing.T) {
+	t.Chdir("../../..")
+	ruleset, err := Load(Paths{C2P: &C2P{
+		PolicyFile: "docs/policy.yaml",
+		ConfigFile: "docs/c2p-config.yaml",
+		EvalDir:    "docs/evals",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ruleset.Bindings) == 0 {
+		t.Fatal("no service bindings were loaded")
+	}
+	for _, binding := range ruleset.Bindings {
+		if _, ok := ruleset.Catalogs[binding.CatalogID]; !ok {
+			t.Errorf("binding %+v references a catalog that was not loaded", binding)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package mapping
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// Waiver accepts the risk of a failing requirement. While active, NOT_COMPLIANT results
+// for matching resources are reported as WAIVED in the current posture.
+type Waiver struct {
+	ID            string `yaml:"id" json:"id"`
+	CatalogID     string `yaml:"catalogId" json:"catalogId"`
+	RequirementID string `yaml:"requirementId" json:"requirementId"`
+	// Resource selects resources with a glob pattern, e.g. "payments-*". Empty matches
+	// every resource.
+	Resource string `yaml:"resource,omitempty" json:"resource,omitempty"`
+	// Tenant limits the waiver to claims from one tenant. Empty matches every tenant.
+	Tenant        string    `yaml:"tenant,omitempty" json:"tenant,omitempty"`
+	Justification string    `yaml:"justification" json:"justification"`
+	Approver      string    `yaml:"approver" json:"approver"`
+	Expires       time.Time `yaml:"expires" json:"expires"`
+}
+
+// Active reports whether the waiver has not expired at the given time.
+func (w Waiver) Active(now time.Time) bool {
+	return now.Before(w.Expires)
+}
+
+func (w Waiver) matches(tenant, catalogID, requirementID, resourceRef string) bool {
+	if w.Tenant != "" && w.Tenant != evidence.TenantName(tenant) {
+		return false
+	}
+	if w.CatalogID != catalogID || !strings.EqualFold(w.RequirementID, requirementID) {
+		return false
+	}
+	if w.Resource == "" {
+		return true
+	}
+	matched, _ := path.Match(w.Resource, resourceRef)
+	return matched
+}
+
+// Waiver returns the active waiver for a requirement and a tenant's resource. When
+// several match, the one expiring last wins.
+func (r *Ruleset) Waiver(tenant, catalogID, requirementID, resourceRef string, now time.Time) (Waiver, bool) {
+	var best *Waiver
+	for i, waiver := range r.Waivers {
+		if !waiver.Active(now) || !waiver.matches(tenant, catalogID, requirementID, resourceRef) {
+			continue
+		}
+		if best == nil || waiver.Expires.After(best.Expires) {
+			best = &r.Waivers[i]
+		}
+	}
+	if best == nil {
+		return Waiver{}, false
+	}
+	return *best, true
+}
+
+func (r *Ruleset) validateWaiver(waiver Waiver) error {
+	if waiver.ID == "" {
+		return errors.New("waiver: id is required")
+	}
+	var errs []error
+	if waiver.Justification == "" {
+		errs = append(errs, errors.New("justification is required"))
+	}
+	if waiver.Approver == "" {
+		errs = append(errs, errors.New("approver is required"))
+	}
+	if waiver.Expires.IsZero() {
+		errs = append(errs, errors.New("expires is required"))
+	}
+	if _, err := path.Match(waiver.Resource, ""); err != nil {
+		errs = append(errs, fmt.Errorf("invalid resource pattern %q: %w", waiver.Resource, err))
+	}
+	if err := r.validateTarget(Target{CatalogID: waiver.CatalogID, RequirementID: waiver.RequirementID}); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("waiver %s: %w", waiver.ID, err)
+	}
+	return nil
+}
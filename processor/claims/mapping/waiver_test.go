@@ -0,0 +1,88 @@
+package mapping
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWaivers = `waivers:
+  - id: WVR-ALL
+    catalogId: TEST-CAT
+    requirementId: cat.t01.tr01
+    justification: Accepted for every resource.
+    approver: security-team
+    expires: 2025-07-01T00:00:00Z
+  - id: WVR-LEGACY
+    catalogId: TEST-CAT
+    requirementId: CAT.T01.TR01
+    resource: legacy-*
+    justification: Legacy images are rebuilt in Q3.
+    approver: security-team
+    expires: 2025-09-01T00:00:00Z
+  - id: WVR-TEAM
+    catalogId: TEST-CAT
+    requirementId: CAT.T01.TR01
+    tenant: team-a
+    justification: Team A accepted the risk.
+    approver: team-a-lead
+    expires: 2025-12-01T00:00:00Z
+`
+
+func TestRulesetWaiver(t *testing.T) {
+	files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "rules.yaml": testWaivers})
+	ruleset, err := Load(Paths{Catalogs: []string{files["catalog.yml"]}, Rules: []string{files["rules.yaml"]}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	june := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	august := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		tenant      string
+		requirement string
+		resource    string
+		now         time.Time
+		want        string
+	}{
+		{"every resource", "", "CAT.T01.TR01", "pod-a", june, "WVR-ALL"},
+		{"latest expiry wins", "", "CAT.T01.TR01", "legacy-api", june, "WVR-LEGACY"},
+		{"expired", "", "CAT.T01.TR01", "pod-a", august, ""},
+		{"glob after other expired", "default", "CAT.T01.TR01", "legacy-api", august, "WVR-LEGACY"},
+		{"tenant waiver", "team-a", "CAT.T01.TR01", "pod-a", august, "WVR-TEAM"},
+		{"other tenant", "team-b", "CAT.T01.TR01", "pod-a", august, ""},
+		{"other requirement", "", "CAT.T01.TR02", "pod-a", june, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waiver, ok := ruleset.Waiver(tt.tenant, "TEST-CAT", tt.requirement, tt.resource, tt.now)
+			if ok != (tt.want != "") || waiver.ID != tt.want {
+				t.Errorf("waiver = %q (found %t), want %q", waiver.ID, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadValidatesWaivers(t *testing.T) {
+	valid := "  - id: WVR-1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n    justification: ok\n    approver: me\n    expires: 2025-07-01T00:00:00Z\n"
+	tests := []struct {
+		name    string
+		waivers string
+		wantErr string
+	}{
+		{"missing id", "  - catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n", "waiver: id is required"},
+		{"missing fields", "  - id: WVR-1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n", "justification is required"},
+		{"unknown requirement", "  - id: WVR-1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR99\n    justification: ok\n    approver: me\n    expires: 2025-07-01T00:00:00Z\n", `requirement "CAT.T01.TR99" not found`},
+		{"bad pattern", strings.Replace(valid, "    justification", "    resource: \"[\"\n    justification", 1), "invalid resource pattern"},
+		{"duplicate id", valid + valid, "waiver WVR-1: duplicate id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "rules.yaml": "waivers:\n" + tt.waivers})
+			_, err := Load(Paths{Catalogs: []string{files["catalog.yml"]}, Rules: []string{files["rules.yaml"]}})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
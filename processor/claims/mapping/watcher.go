@@ -0,0 +1,82 @@
+package mapping
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultReloadInterval is how often configured files are checked for changes.
+const DefaultReloadInterval = 10 * time.Second
+
+// Watcher holds the current Ruleset and reloads it when the configured files change.
+// Files are polled rather than watched with inotify so that editors and config map
+// updates that replace files through renames or symlinks are picked up reliably.
+type Watcher struct {
+	paths    Paths
+	interval time.Duration
+	current  atomic.Pointer[Ruleset]
+	// rejected is the last revision that failed validation, so it is only reported once.
+	rejected string
+}
+
+// NewWatcher loads the initial Ruleset. An error is returned if it is invalid.
+func NewWatcher(paths Paths, interval time.Duration) (*Watcher, error) {
+	ruleset, err := Load(paths)
+	if err != nil {
+		return nil, err
+	}
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	w := &Watcher{
+		paths:    paths,
+		interval: interval,
+	}
+	w.current.Store(ruleset)
+	return w, nil
+}
+
+// Current returns the active Ruleset. Callers should use the returned value for the
+// whole unit of work so that a concurrent reload does not mix revisions.
+func (w *Watcher) Current() *Ruleset {
+	return w.current.Load()
+}
+
+// Run polls the configured files until the context is canceled.
+func (w *Watcher) Run(ctx context.Context) {
+	if w.paths.Empty() {
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.reload()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reload swaps in a new Ruleset if the files changed and the new version is valid.
+func (w *Watcher) reload() {
+	loaded, err := read(w.paths)
+	if err != nil {
+		log.Printf("Keeping ruleset %s: %v", w.Current().Revision, err)
+		return
+	}
+	if loaded.revision == w.Current().Revision || loaded.revision == w.rejected {
+		return
+	}
+	ruleset, err := parse(loaded)
+	if err != nil {
+		w.rejected = loaded.revision
+		log.Printf("Rejected ruleset %s, keeping %s: %v", loaded.revision, w.Current().Revision, err)
+		return
+	}
+	previous := w.current.Swap(ruleset)
+	log.Printf("Reloaded ruleset %s (previous %s)", ruleset.Revision, previous.Revision)
+}
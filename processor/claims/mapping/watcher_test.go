@@ -0,0 +1,101 @@
+package mapping
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWatcherReload(t *testing.T) {
+	rule := "rules:\n  - policyId: p1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n"
+	files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "rules.yaml": rule})
+	paths := Paths{Catalogs: []string{files["catalog.yml"]}, Rules: []string{files["rules.yaml"]}}
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(files["rules.yaml"], []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	watcher, err := NewWatcher(paths, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := watcher.Current()
+
+	// Unchanged files keep the same ruleset.
+	watcher.reload()
+	if watcher.Current() != initial {
+		t.Fatal("reload without changes replaced the ruleset")
+	}
+
+	// A valid change is swapped in.
+	write(rule + "  - policyId: p2\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n")
+	watcher.reload()
+	reloaded := watcher.Current()
+	if reloaded.Revision == initial.Revision || len(reloaded.Rules) != 2 {
+		t.Fatalf("ruleset %s with %d rules, want a new revision with 2 rules", reloaded.Revision, len(reloaded.Rules))
+	}
+
+	// An invalid revision is rejected and the last good ruleset is kept.
+	write("rules:\n  - policyId: p1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR99\n")
+	watcher.reload()
+	if watcher.Current() != reloaded {
+		t.Fatalf("invalid ruleset replaced revision %s with %s", reloaded.Revision, watcher.Current().Revision)
+	}
+	if watcher.rejected == "" {
+		t.Error("rejected revision was not recorded")
+	}
+
+	// Unreadable files keep the last good ruleset too.
+	if err := os.Remove(files["rules.yaml"]); err != nil {
+		t.Fatal(err)
+	}
+	watcher.reload()
+	if watcher.Current() != reloaded {
+		t.Fatal("a missing file replaced the ruleset")
+	}
+
+	// Restoring the original files loads them again.
+	write(rule)
+	watcher.reload()
+	if watcher.Current().Revision != initial.Revision {
+		t.Errorf("revision = %s, want %s", watcher.Current().Revision, initial.Revision)
+	}
+}
+
+func TestWatcherRun(t *testing.T) {
+	files := writeFiles(t, map[string]string{"catalog.yml": testCatalog, "rules.yaml": "rules: []\n"})
+	watcher, err := NewWatcher(Paths{Catalogs: []string{files["catalog.yml"]}, Rules: []string{files["rules.yaml"]}}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	initial := watcher.Current().Revision
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(done)
+	}()
+
+	if err := os.WriteFile(files["rules.yaml"], []byte("rules:\n  - policyId: p1\n    catalogId: TEST-CAT\n    requirementId: CAT.T01.TR01\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for watcher.Current().Revision == initial {
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not reload the changed rules")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestNewWatcherRejectsInvalidRuleset(t *testing.T) {
+	files := writeFiles(t, map[string]string{"rules.yaml": "rules:\n  - policyId: p1\n    catalogId: MISSING\n    requirementId: X\n"})
+	if _, err := NewWatcher(Paths{Rules: []string{files["rules.yaml"]}}, time.Hour); err == nil {
+		t.Fatal("expected an error for an invalid initial ruleset")
+	}
+}
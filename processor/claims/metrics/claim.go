@@ -14,20 +14,26 @@ import (
 type ComplianceObserver struct {
 	meter           *metric.Meter
 	observableGauge metric.Float64ObservableGauge
-	store           *claims.Store
+	posture         Posture
+}
+
+// Posture provides the current claims reported by the compliance gauges.
+type Posture interface {
+	// Current returns the claims that make up the current posture.
+	Current() ([]claims.ConformanceClaim, error)
 }
 
 // NewComplianceObserver creates a new ComplianceObserver and registers the callback.
-func NewComplianceObserver(meter metric.Meter, store *claims.Store) (*ComplianceObserver, error) {
+func NewComplianceObserver(meter metric.Meter, posture Posture) (*ComplianceObserver, error) {
 	co := &ComplianceObserver{
-		meter: &meter,
-		store: store,
+		meter:   &meter,
+		posture: posture,
 	}
 
 	var err error
 	co.observableGauge, err = meter.Float64ObservableGauge(
 		"compliance_assessment_status",
-		metric.WithDescription("Current compliance assessment status (1=COMPLIANT, 0=NOT_COMPLIANT, -1=NOT_APPLICABLE)"),
+		metric.WithDescription(statusDescription("Current compliance assessment status")),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create observable gauge: %w", err)
@@ -42,31 +48,29 @@ func NewComplianceObserver(meter metric.Meter, store *claims.Store) (*Compliance
 }
 
 // observeComplianceCallback is the callback function for the observable gauge.
-// It iterates through the compliance data store and observes the status for each claim.
+// It observes the status of each claim in the current posture. Superseded claims are not reported.
 func (co *ComplianceObserver) observeComplianceCallback(ctx context.Context, o metric.Observer) error {
-	allClaims := co.store.GetClaims()
+	allClaims, err := co.posture.Current()
+	if err != nil {
+		return fmt.Errorf("failed to read claims: %w", err)
+	}
 	for _, claim := range allClaims {
 		for _, method := range claim.Assessment.Methods {
-			statusValue := 0.0
-
-			switch method.Result.Status {
-			case "COMPLIANT":
-				statusValue = 1.0
-			case "NOT_COMPLIANT":
-				statusValue = 0.0
-			case "NOT_APPLICABLE":
-				statusValue = -1.0
-			default:
-				statusValue = 0.0
+			status := claims.StatusUnknown
+			if method.Result != nil && method.Result.Status != "" {
+				status = string(method.Result.Status)
 			}
+			statusValue := StatusValue(status)
 
 			attributes := metric.WithAttributes(
+				attribute.String("tenant", claim.TenantID()),
 				attribute.String("resource", claim.ResourceRef),
 				attribute.String("requirement_id", claim.Assessment.RequirementID),
 				attribute.String("attestation_id", claim.ClaimID),
 				attribute.String("method_id", method.Name),
 				attribute.String("baseline_id", claim.CatalogID),
-				attribute.String("assessment_status_raw", string(method.Result.Status)),
+				attribute.String("assessment_status_raw", status),
+				attribute.Bool("derived", claim.DerivedFrom != ""),
 			)
 
 			o.ObserveFloat64(co.observableGauge, statusValue, attributes)
@@ -74,3 +78,44 @@ func (co *ComplianceObserver) observeComplianceCallback(ctx context.Context, o m
 	}
 	return nil
 }
+
+// Gauge values for each assessment status. Unresolved statuses are negative so they
+// are never mistaken for a pass, and are distinct from NOT_COMPLIANT.
+const (
+	compliantValue     = 1.0
+	notCompliantValue  = 0.0
+	notApplicableValue = -1.0
+	needsReviewValue   = -2.0
+	unknownValue       = -3.0
+	errorValue         = -4.0
+	staleValue         = -5.0
+	waivedValue        = 2.0
+)
+
+// StatusValue returns the gauge encoding for an assessment status.
+// Empty or unrecognized statuses are reported as UNKNOWN.
+func StatusValue(status string) float64 {
+	switch status {
+	case claims.StatusCompliant:
+		return compliantValue
+	case claims.StatusNotCompliant:
+		return notCompliantValue
+	case claims.StatusNotApplicable:
+		return notApplicableValue
+	case claims.StatusNeedsReview:
+		return needsReviewValue
+	case claims.StatusError:
+		return errorValue
+	case claims.StatusWaived:
+		return waivedValue
+	case claims.StatusStale:
+		return staleValue
+	default:
+		return unknownValue
+	}
+}
+
+func statusDescription(prefix string) string {
+	return fmt.Sprintf("%s (%v=COMPLIANT, %v=NOT_COMPLIANT, %v=NOT_APPLICABLE, %v=NEEDS_REVIEW, %v=UNKNOWN, %v=ERROR, %v=STALE, %v=WAIVED)",
+		prefix, compliantValue, notCompliantValue, notApplicableValue, needsReviewValue, unknownValue, errorValue, staleValue, waivedValue)
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// TestStatusValue pins the gauge encoding that dashboards and alerting rules depend on.
+func TestStatusValue(t *testing.T) {
+	tests := []struct {
+		status string
+		want   float64
+	}{
+		{claims.StatusCompliant, 1},
+		{claims.StatusNotCompliant, 0},
+		{claims.StatusNotApplicable, -1},
+		{claims.StatusNeedsReview, -2},
+		{claims.StatusUnknown, -3},
+		{claims.StatusError, -4},
+		{claims.StatusStale, -5},
+		{claims.StatusWaived, 2},
+		{"", -3},
+		{"PASSED", -3},
+	}
+	for _, tt := range tests {
+		if got := StatusValue(tt.status); got != tt.want {
+			t.Errorf("StatusValue(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+	if len(claims.Statuses) != 8 {
+		t.Errorf("%d statuses, want a gauge value pinned for each of 8", len(claims.Statuses))
+	}
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// StoreObserver reports the number of claims held in the claim store.
+type StoreObserver struct {
+	observableGauge metric.Int64ObservableGauge
+	store           claims.Store
+}
+
+// NewStoreObserver creates a new StoreObserver and registers the callback.
+func NewStoreObserver(meter metric.Meter, store claims.Store) (*StoreObserver, error) {
+	so := &StoreObserver{store: store}
+
+	var err error
+	so.observableGauge, err = meter.Int64ObservableGauge(
+		"claim_store_claims",
+		metric.WithDescription("Number of claims in the claim store, split by whether they are part of the current posture"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create observable gauge: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(so.observeStoreCallback, so.observableGauge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register callback: %w", err)
+	}
+
+	return so, nil
+}
+
+func (so *StoreObserver) observeStoreCallback(ctx context.Context, o metric.Observer) error {
+	allClaims, err := so.store.GetClaims()
+	if err != nil {
+		return fmt.Errorf("failed to read claims: %w", err)
+	}
+	var current int64
+	for _, claim := range allClaims {
+		if claim.Current() {
+			current++
+		}
+	}
+	o.ObserveInt64(so.observableGauge, current, metric.WithAttributes(attribute.Bool("current", true)))
+	o.ObserveInt64(so.observableGauge, int64(len(allClaims))-current, metric.WithAttributes(attribute.Bool("current", false)))
+	return nil
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/jpower432/shiny-journey/processor/claims/aggregate"
+)
+
+// VerdictObserver handles observing requirement-level verdicts aggregated across methods.
+type VerdictObserver struct {
+	observableGauge metric.Float64ObservableGauge
+	posture         Posture
+	aggregator      *aggregate.Aggregator
+}
+
+// NewVerdictObserver creates a new VerdictObserver and registers the callback.
+func NewVerdictObserver(meter metric.Meter, posture Posture, aggregator *aggregate.Aggregator) (*VerdictObserver, error) {
+	vo := &VerdictObserver{
+		posture:    posture,
+		aggregator: aggregator,
+	}
+
+	var err error
+	vo.observableGauge, err = meter.Float64ObservableGauge(
+		"compliance_requirement_verdict",
+		metric.WithDescription(statusDescription("Requirement verdict per resource aggregated across methods")),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create observable gauge: %w", err)
+	}
+
+	_, err = meter.RegisterCallback(vo.observeVerdictCallback, vo.observableGauge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register callback: %w", err)
+	}
+
+	return vo, nil
+}
+
+// observeVerdictCallback is the callback function for the observable gauge.
+// It aggregates the current claims and observes one verdict per tenant, requirement, and resource.
+func (vo *VerdictObserver) observeVerdictCallback(ctx context.Context, o metric.Observer) error {
+	allClaims, err := vo.posture.Current()
+	if err != nil {
+		return fmt.Errorf("failed to read claims: %w", err)
+	}
+	for _, verdict := range vo.aggregator.Aggregate(allClaims) {
+		statusValue := StatusValue(verdict.Status)
+
+		attributes := metric.WithAttributes(
+			attribute.String("tenant", verdict.Tenant),
+			attribute.String("resource", verdict.ResourceRef),
+			attribute.String("requirement_id", verdict.RequirementID),
+			attribute.String("baseline_id", verdict.CatalogID),
+			attribute.String("strategy", string(verdict.Strategy)),
+			attribute.Bool("conflict", verdict.Conflict),
+			attribute.Int("method_count", len(verdict.Methods)),
+			attribute.String("assessment_status_raw", verdict.Status),
+		)
+
+		o.ObserveFloat64(vo.observableGauge, statusValue, attributes)
+	}
+	return nil
+}
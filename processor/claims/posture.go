@@ -0,0 +1,121 @@
+package claims
+
+import "fmt"
+
+// PostureKey identifies one entry in the current posture. The newest claim for each key
+// supersedes older claims with the same key.
+type PostureKey struct {
+	Tenant        string
+	CatalogID     string
+	RequirementID string
+	ResourceRef   string
+	Method        string
+}
+
+// String encodes the key for use in storage keys.
+func (k PostureKey) String() string {
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", k.Tenant, k.CatalogID, k.RequirementID, k.ResourceRef, k.Method)
+}
+
+// PostureKeys returns the posture keys the claim reports on, one per assessment method.
+func (c ConformanceClaim) PostureKeys() []PostureKey {
+	key := PostureKey{
+		Tenant:        c.TenantID(),
+		CatalogID:     c.CatalogID,
+		RequirementID: c.Assessment.RequirementID,
+		ResourceRef:   c.ResourceRef,
+	}
+	if len(c.Assessment.Methods) == 0 {
+		return []PostureKey{key}
+	}
+	keys := make([]PostureKey, 0, len(c.Assessment.Methods))
+	for _, method := range c.Assessment.Methods {
+		key.Method = method.Name
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Current reports whether the claim is part of the current posture.
+func (c ConformanceClaim) Current() bool {
+	return c.SupersededBy == ""
+}
+
+// newerThan orders claims by timestamp, breaking ties by claim ID.
+func (c ConformanceClaim) newerThan(other ConformanceClaim) bool {
+	return claimBefore(other.Timestamp, other.ClaimID, c.Timestamp, c.ClaimID)
+}
+
+// PostureIndex is the storage a Store uses to track the current claim for each posture key.
+type PostureIndex interface {
+	// CurrentID returns the ID of the current claim for the key, or an empty string.
+	CurrentID(key PostureKey) (string, error)
+	// SetCurrent records the claim as current for the key.
+	SetCurrent(key PostureKey, claimID string) error
+	// Claim returns the stored claim with the given ID.
+	Claim(claimID string) (ConformanceClaim, bool, error)
+}
+
+// UpdatePosture makes the claim current for every posture key where it is the newest
+// claim. It sets SupersededBy on the claim when an existing claim is newer for all of its
+// keys, and returns previously current claims that are now superseded so the store can
+// save them.
+func UpdatePosture(index PostureIndex, claim *ConformanceClaim) ([]ConformanceClaim, error) {
+	claim.SupersededBy = ""
+	replaced := make(map[string]ConformanceClaim)
+	var newest string
+	current := false
+	for _, key := range claim.PostureKeys() {
+		currentID, err := index.CurrentID(key)
+		if err != nil {
+			return nil, err
+		}
+		if currentID != "" && currentID != claim.ClaimID {
+			existing, ok, err := index.Claim(currentID)
+			if err != nil {
+				return nil, err
+			}
+			if ok && existing.newerThan(*claim) {
+				newest = existing.ClaimID
+				continue
+			}
+			if ok {
+				replaced[existing.ClaimID] = existing
+			}
+		}
+		if err := index.SetCurrent(key, claim.ClaimID); err != nil {
+			return nil, err
+		}
+		current = true
+	}
+	if !current {
+		claim.SupersededBy = newest
+	}
+
+	var superseded []ConformanceClaim
+	for _, old := range replaced {
+		stillCurrent, err := isCurrent(index, old)
+		if err != nil {
+			return nil, err
+		}
+		if !stillCurrent {
+			old.SupersededBy = claim.ClaimID
+			superseded = append(superseded, old)
+		}
+	}
+	return superseded, nil
+}
+
+// isCurrent reports whether the claim is still current for any of its posture keys.
+func isCurrent(index PostureIndex, claim ConformanceClaim) (bool, error) {
+	for _, key := range claim.PostureKeys() {
+		currentID, err := index.CurrentID(key)
+		if err != nil {
+			return false, err
+		}
+		if currentID == claim.ClaimID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,218 @@
+package claims
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+	"github.com/jpower432/shiny-journey/processor/version"
+)
+
+// Provenance records the inputs a claim was produced from, so it can be reproduced.
+type Provenance struct {
+	// EvidenceDigest is the sha256 digest of the canonical JSON of the raw evidence
+	// document, as sha256:<hex>.
+	EvidenceDigest string `json:"evidenceDigest,omitempty"`
+	// EvidenceDigests are the digests of the canonical JSON of the raw evidence document
+	// by algorithm name, e.g. sha256 and sha512.
+	EvidenceDigests map[string]string `json:"evidenceDigests,omitempty"`
+	// EvidenceTimestamp is when the policy engine reported the raw evidence. Evidence
+	// freshness is measured from it rather than from when the claim was made.
+	EvidenceTimestamp *time.Time `json:"evidenceTimestamp,omitempty"`
+	// Mapping describes the rule, plan, default target, or crosswalk that mapped the evidence.
+	Mapping string `json:"mapping,omitempty"`
+	// MappingRevision is the revision of the file the mapping was loaded from.
+	MappingRevision string `json:"mappingRevision,omitempty"`
+	// CatalogVersion is the version of the catalog declaring the requirement.
+	CatalogVersion string `json:"catalogVersion,omitempty"`
+	// AgentVersion is the build version of the agent that produced the claim.
+	AgentVersion string `json:"agentVersion,omitempty"`
+}
+
+// RecordEvidence records the digests of the canonical JSON of the raw evidence
+// document for each algorithm. The sha256 digest is always recorded.
+func (p *Provenance) RecordEvidence(data []byte, algorithms ...cryptoutil.DigestValue) error {
+	canonical, err := evidence.Canonicalize(data)
+	if err != nil {
+		return err
+	}
+	p.EvidenceDigest = evidence.Digest(canonical)
+	digests, err := evidence.Digests(canonical, append([]cryptoutil.DigestValue{evidence.RefAlgorithm}, algorithms...)...)
+	if err != nil {
+		return err
+	}
+	p.EvidenceDigests, err = digests.ToNameMap()
+	return err
+}
+
+// checkEvidence compares the digests of the raw evidence document with the recorded
+// digests. Digests recorded before evidence was canonicalized are of the document as
+// it was stored, so the digest of the document itself is accepted too.
+func (p Provenance) checkEvidence(data []byte) []string {
+	var differences []string
+	var actual Provenance
+	if err := actual.RecordEvidence(data, recordedAlgorithms(p.EvidenceDigests)...); err != nil {
+		return []string{fmt.Sprintf("evidence is not valid JSON: %v", err)}
+	}
+	if p.EvidenceDigest != "" && p.EvidenceDigest != actual.EvidenceDigest && p.EvidenceDigest != evidence.Digest(data) {
+		differences = append(differences, fmt.Sprintf("evidence digest is %s, claim records %s", actual.EvidenceDigest, p.EvidenceDigest))
+	}
+	for _, name := range sortedKeys(p.EvidenceDigests) {
+		recorded, computed := p.EvidenceDigests[name], actual.EvidenceDigests[name]
+		switch {
+		case computed == "":
+			differences = append(differences, fmt.Sprintf("evidence %s digest cannot be computed, claim records %s", name, recorded))
+		case computed != recorded:
+			differences = append(differences, fmt.Sprintf("evidence %s digest is %s, claim records %s", name, computed, recorded))
+		}
+	}
+	return differences
+}
+
+// recordedAlgorithms returns the algorithms of recorded digests, skipping unknown names.
+func recordedAlgorithms(digests map[string]string) []cryptoutil.DigestValue {
+	var algorithms []cryptoutil.DigestValue
+	for name := range digests {
+		if hash, err := cryptoutil.HashFromString(name); err == nil {
+			algorithms = append(algorithms, cryptoutil.DigestValue{Hash: hash})
+		}
+	}
+	return algorithms
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Reproduction is the result of re-deriving a claim from its raw evidence.
+type Reproduction struct {
+	ClaimID string `json:"claimId"`
+	// Reproducible reports whether the evidence matches the recorded digest and the
+	// re-derived claim has the same requirement and method results.
+	Reproducible bool `json:"reproducible"`
+	// Differences lists why the claim was not reproduced.
+	Differences []string `json:"differences,omitempty"`
+	// Warnings lists provenance that differs without changing the verdict, such as a
+	// different agent version.
+	Warnings []string `json:"warnings,omitempty"`
+	// Claim is the re-derived claim.
+	Claim *ConformanceClaim `json:"claim,omitempty"`
+}
+
+// Reproduce re-runs the mapping of the raw evidence with the ruleset and compares the
+// result with the recorded claim. The ruleset should be loaded from the files at the
+// revisions recorded on the claim.
+func Reproduce(claim ConformanceClaim, evidenceData []byte, ruleset *mapping.Ruleset) (Reproduction, error) {
+	result := Reproduction{ClaimID: claim.ClaimID}
+	var recorded Provenance
+	if claim.Provenance != nil {
+		recorded = *claim.Provenance
+	} else {
+		result.Warnings = append(result.Warnings, "claim has no recorded provenance")
+	}
+	result.Differences = append(result.Differences, recorded.checkEvidence(evidenceData)...)
+
+	var rawEv evidence.RawEvidence
+	if err := json.Unmarshal(evidenceData, &rawEv); err != nil {
+		return result, fmt.Errorf("invalid raw evidence: %w", err)
+	}
+	if !SupportsSource(rawEv.Source) {
+		return result, fmt.Errorf("unsupported evidence source %q", rawEv.Source)
+	}
+	rederived, err := NewFromEvidence(rawEv, claim.RawEvidenceRef, ruleset)
+	if err != nil {
+		result.Differences = append(result.Differences, fmt.Sprintf("evidence no longer maps to a requirement: %v", err))
+		return result, nil
+	}
+	if claim.DerivedFrom != "" {
+		rederived = deriveMatching(*rederived, claim, ruleset)
+		if rederived == nil {
+			result.Differences = append(result.Differences,
+				fmt.Sprintf("no crosswalk derives %s %s", claim.CatalogID, claim.Assessment.RequirementID))
+			return result, nil
+		}
+	}
+	if err := rederived.Provenance.RecordEvidence(evidenceData, recordedAlgorithms(recorded.EvidenceDigests)...); err != nil {
+		return result, err
+	}
+	result.Claim = rederived
+
+	compare := func(field, recorded, actual string) {
+		if !strings.EqualFold(recorded, actual) {
+			result.Differences = append(result.Differences, fmt.Sprintf("%s is %q, claim records %q", field, actual, recorded))
+		}
+	}
+	compare("tenant", claim.TenantID(), rederived.TenantID())
+	compare("catalog", claim.CatalogID, rederived.CatalogID)
+	compare("control", claim.ControlID, rederived.ControlID)
+	compare("requirement", claim.Assessment.RequirementID, rederived.Assessment.RequirementID)
+	if len(claim.Assessment.Methods) != len(rederived.Assessment.Methods) {
+		result.Differences = append(result.Differences, fmt.Sprintf("re-derived claim has %d methods, claim records %d",
+			len(rederived.Assessment.Methods), len(claim.Assessment.Methods)))
+	} else {
+		for i, method := range claim.Assessment.Methods {
+			actual := rederived.Assessment.Methods[i]
+			compare("method name", method.Name, actual.Name)
+			compare(fmt.Sprintf("method %s status", method.Name), resultStatus(method.Result), resultStatus(actual.Result))
+		}
+	}
+
+	warn := func(field, recorded, actual string) {
+		if recorded != "" && recorded != actual {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s is %q, claim records %q", field, actual, recorded))
+		}
+	}
+	warn("ruleset revision", claim.ConfigRevision, ruleset.Revision)
+	warn("mapping", recorded.Mapping, rederived.Provenance.Mapping)
+	warn("mapping revision", recorded.MappingRevision, rederived.Provenance.MappingRevision)
+	warn("catalog version", recorded.CatalogVersion, rederived.Provenance.CatalogVersion)
+	warn("agent version", recorded.AgentVersion, rederived.Provenance.AgentVersion)
+
+	result.Reproducible = len(result.Differences) == 0
+	return result, nil
+}
+
+// deriveMatching returns the claim derived from native for the catalog and requirement
+// of the recorded derived claim, or nil when the ruleset no longer derives it.
+func deriveMatching(native, recorded ConformanceClaim, ruleset *mapping.Ruleset) *ConformanceClaim {
+	target := mapping.Target{
+		CatalogID:     native.CatalogID,
+		ControlID:     native.ControlID,
+		RequirementID: native.Assessment.RequirementID,
+	}
+	for _, derivation := range ruleset.Derive(target) {
+		if derivation.Target.CatalogID == recorded.CatalogID &&
+			strings.EqualFold(derivation.Target.RequirementID, recorded.Assessment.RequirementID) {
+			return Derive(native, derivation)
+		}
+	}
+	return nil
+}
+
+func resultStatus(result *layer4.AssessmentResult) string {
+	if result == nil {
+		return ""
+	}
+	return string(result.Status)
+}
+
+func newProvenance(resolution mapping.Resolution) *Provenance {
+	return &Provenance{
+		Mapping:         resolution.Mapping,
+		MappingRevision: resolution.MappingRevision,
+		CatalogVersion:  resolution.CatalogVersion,
+		AgentVersion:    version.Get(),
+	}
+}
@@ -0,0 +1,126 @@
+package claims
+
+import (
+	"crypto"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// recordedClaim returns an OPA claim for the decision, made and recorded the way the
+// agent does, along with the raw evidence document.
+func recordedClaim(t *testing.T, decision string) (ConformanceClaim, []byte) {
+	t.Helper()
+	rawEv := evidence.RawEvidence{
+		Metadata: evidence.Metadata{ID: "ev-1", Timestamp: testTime, Source: "OPA", PolicyID: "p1", Decision: decision},
+		Resource: evidence.Resource{Name: "pod-a"},
+	}
+	data, err := json.Marshal(rawEv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claim, err := NewFromEvidence(rawEv, evidence.Digest(data), mapping.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := claim.Provenance.RecordEvidence(data, cryptoutil.DigestValue{Hash: crypto.SHA512}); err != nil {
+		t.Fatal(err)
+	}
+	return *claim, data
+}
+
+func TestReproduce(t *testing.T) {
+	claim, data := recordedClaim(t, "allow")
+	_, denied := recordedClaim(t, "deny")
+	indented, err := json.MarshalIndent(json.RawMessage(data), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited := claim
+	edited.Assessment.Methods = append(edited.Assessment.Methods[:0:0], edited.Assessment.Methods...)
+	result := *edited.Assessment.Methods[0].Result
+	setStatus(&result.Status, StatusNotCompliant)
+	edited.Assessment.Methods[0].Result = &result
+
+	unrecorded := claim
+	unrecorded.Provenance = nil
+
+	oldAgent := claim
+	provenance := *claim.Provenance
+	provenance.AgentVersion = "v0.0.1"
+	oldAgent.Provenance = &provenance
+
+	tests := []struct {
+		name            string
+		claim           ConformanceClaim
+		evidence        []byte
+		reproducible    bool
+		wantDifferences []string
+		wantWarnings    []string
+	}{
+		{name: "same evidence", claim: claim, evidence: data, reproducible: true},
+		{name: "reformatted evidence", claim: claim, evidence: indented, reproducible: true},
+		{
+			name:            "changed evidence",
+			claim:           claim,
+			evidence:        denied,
+			wantDifferences: []string{"evidence digest is", "evidence sha256 digest is", "evidence sha512 digest is", `method OPA status is "NOT_COMPLIANT"`},
+		},
+		{name: "edited claim", claim: edited, evidence: data, wantDifferences: []string{`method OPA status is "COMPLIANT", claim records "NOT_COMPLIANT"`}},
+		{name: "no provenance", claim: unrecorded, evidence: data, reproducible: true, wantWarnings: []string{"claim has no recorded provenance"}},
+		{name: "different agent", claim: oldAgent, evidence: data, reproducible: true, wantWarnings: []string{`claim records "v0.0.1"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reproduction, err := Reproduce(tt.claim, tt.evidence, mapping.Default())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reproduction.Reproducible != tt.reproducible {
+				t.Errorf("reproducible = %t, want %t (differences %v)", reproduction.Reproducible, tt.reproducible, reproduction.Differences)
+			}
+			if len(reproduction.Differences) != len(tt.wantDifferences) {
+				t.Fatalf("differences = %v, want %d", reproduction.Differences, len(tt.wantDifferences))
+			}
+			for i, want := range tt.wantDifferences {
+				if !strings.Contains(reproduction.Differences[i], want) {
+					t.Errorf("difference %d = %q, want it to contain %q", i, reproduction.Differences[i], want)
+				}
+			}
+			if len(reproduction.Warnings) != len(tt.wantWarnings) {
+				t.Fatalf("warnings = %v, want %d", reproduction.Warnings, len(tt.wantWarnings))
+			}
+			for i, want := range tt.wantWarnings {
+				if !strings.Contains(reproduction.Warnings[i], want) {
+					t.Errorf("warning %d = %q, want it to contain %q", i, reproduction.Warnings[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestReproduceRejectsInvalidEvidence(t *testing.T) {
+	claim, _ := recordedClaim(t, "allow")
+	tests := []struct {
+		name     string
+		evidence string
+		wantErr  string
+	}{
+		{"not JSON", "not json", "invalid raw evidence"},
+		{"unsupported source", `{"id":"ev-1","source":"Falco","decision":"allow"}`, `unsupported evidence source "Falco"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Reproduce(claim, []byte(tt.evidence), mapping.Default())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
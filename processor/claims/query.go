@@ -0,0 +1,177 @@
+package claims
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortOrder orders query results by claim timestamp.
+type SortOrder string
+
+const (
+	Ascending  SortOrder = "asc"
+	Descending SortOrder = "desc"
+)
+
+// Query selects stored claims. Empty fields match every claim.
+type Query struct {
+	// Tenant matches claims reported by the tenant. The default tenant also matches claims
+	// recorded without a tenant.
+	Tenant        string
+	CatalogID     string
+	ControlID     string
+	RequirementID string
+	ResourceRef   string
+	// Status matches claims with at least one method result in the status.
+	Status string
+	// Source matches claims with at least one method from the evidence source.
+	Source string
+	// CurrentOnly excludes superseded claims.
+	CurrentOnly bool
+	// Start and End select claims timestamped within [Start, End).
+	Start time.Time
+	End   time.Time
+	// Order defaults to Ascending.
+	Order SortOrder
+	// Limit caps the number of claims returned. Zero returns every match.
+	Limit int
+	// Cursor resumes a previous query after its last returned claim.
+	Cursor string
+}
+
+// QueryResult is one page of claims matching a query.
+type QueryResult struct {
+	Claims []ConformanceClaim `json:"claims"`
+	// Total is the number of claims matching the query across all pages.
+	Total int `json:"total"`
+	// NextCursor fetches the next page. It is empty on the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Validate checks the query for unsupported values.
+func (q Query) Validate() error {
+	switch q.Order {
+	case "", Ascending, Descending:
+	default:
+		return fmt.Errorf("unknown sort order %q", q.Order)
+	}
+	if q.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.Start.Before(q.End) {
+		return fmt.Errorf("start %s must be before end %s", q.Start.Format(time.RFC3339), q.End.Format(time.RFC3339))
+	}
+	if q.Cursor != "" {
+		if _, _, err := decodeCursor(q.Cursor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the claim satisfies the query filters.
+func (q Query) Matches(claim ConformanceClaim) bool {
+	if q.Tenant != "" && claim.TenantID() != q.Tenant {
+		return false
+	}
+	if q.CatalogID != "" && claim.CatalogID != q.CatalogID {
+		return false
+	}
+	if q.ControlID != "" && claim.ControlID != q.ControlID {
+		return false
+	}
+	if q.RequirementID != "" && claim.Assessment.RequirementID != q.RequirementID {
+		return false
+	}
+	if q.ResourceRef != "" && claim.ResourceRef != q.ResourceRef {
+		return false
+	}
+	if q.CurrentOnly && !claim.Current() {
+		return false
+	}
+	if !InRange(claim.Timestamp, q.Start, q.End) {
+		return false
+	}
+	if q.Status == "" && q.Source == "" {
+		return true
+	}
+	for _, method := range claim.Assessment.Methods {
+		if q.Source != "" && method.Name != q.Source {
+			continue
+		}
+		if q.Status != "" && (method.Result == nil || string(method.Result.Status) != q.Status) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// Page sorts the claims matching the query and returns the page selected by its cursor and limit.
+// Stores call it with a candidate set narrowed by their indexes.
+func Page(q Query, candidates []ConformanceClaim) (QueryResult, error) {
+	if err := q.Validate(); err != nil {
+		return QueryResult{}, err
+	}
+	matches := make([]ConformanceClaim, 0, len(candidates))
+	for _, claim := range candidates {
+		if q.Matches(claim) {
+			matches = append(matches, claim)
+		}
+	}
+	descending := q.Order == Descending
+	sort.Slice(matches, func(i, j int) bool {
+		return claimBefore(matches[i].Timestamp, matches[i].ClaimID, matches[j].Timestamp, matches[j].ClaimID) != descending
+	})
+
+	result := QueryResult{Total: len(matches)}
+	if q.Cursor != "" {
+		after, afterID, _ := decodeCursor(q.Cursor)
+		start := sort.Search(len(matches), func(i int) bool {
+			if descending {
+				return claimBefore(matches[i].Timestamp, matches[i].ClaimID, after, afterID)
+			}
+			return claimBefore(after, afterID, matches[i].Timestamp, matches[i].ClaimID)
+		})
+		matches = matches[start:]
+	}
+	if q.Limit > 0 && len(matches) > q.Limit {
+		matches = matches[:q.Limit]
+		last := matches[len(matches)-1]
+		result.NextCursor = encodeCursor(last.Timestamp, last.ClaimID)
+	}
+	result.Claims = matches
+	return result, nil
+}
+
+// claimBefore orders claims by timestamp, breaking ties by claim ID.
+func claimBefore(t time.Time, id string, u time.Time, otherID string) bool {
+	if !t.Equal(u) {
+		return t.Before(u)
+	}
+	return id < otherID
+}
+
+func encodeCursor(t time.Time, claimID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(t.UnixNano(), 10) + ":" + claimID))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	nanos, claimID, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, "", fmt.Errorf("invalid cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return time.Time{}, "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	return time.Unix(0, n), claimID, nil
+}
@@ -0,0 +1,183 @@
+// Package retention evicts old claims from a claim store so it does not grow without bound.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// DefaultCompactionInterval is how often the store is compacted.
+const DefaultCompactionInterval = time.Hour
+
+// Reasons a claim is evicted.
+const (
+	ReasonMaxAge            = "max_age"
+	ReasonMaxPerRequirement = "max_per_requirement"
+)
+
+// Policy decides which claims are kept. Claims in the current posture and claims under
+// legal hold are always kept. Zero values disable each limit.
+type Policy struct {
+	// MaxAge evicts claims older than the duration.
+	MaxAge time.Duration
+	// MaxPerRequirement caps the claims kept for each tenant, catalog, requirement, and resource.
+	MaxPerRequirement int
+	// KeepLatest always keeps the newest claims for each catalog, requirement, resource,
+	// and method, regardless of the other limits.
+	KeepLatest int
+}
+
+// Enabled reports whether the policy evicts anything.
+func (p Policy) Enabled() bool {
+	return p.MaxAge > 0 || p.MaxPerRequirement > 0
+}
+
+// Validate checks the policy for negative limits.
+func (p Policy) Validate() error {
+	if p.MaxAge < 0 || p.MaxPerRequirement < 0 || p.KeepLatest < 0 {
+		return fmt.Errorf("retention limits must not be negative")
+	}
+	return nil
+}
+
+// Eviction is a claim selected for removal.
+type Eviction struct {
+	Claim  claims.ConformanceClaim
+	Reason string
+}
+
+type requirementKey struct {
+	tenant, catalogID, requirementID, resourceRef string
+}
+
+// Select returns the claims the policy evicts at the given time.
+func (p Policy) Select(all []claims.ConformanceClaim, now time.Time) []Eviction {
+	sorted := append([]claims.ConformanceClaim(nil), all...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.After(sorted[j].Timestamp)
+		}
+		return sorted[i].ClaimID > sorted[j].ClaimID
+	})
+
+	protected := make(map[string]bool)
+	latest := make(map[claims.PostureKey]int)
+	for _, claim := range sorted {
+		if claim.Current() || claim.LegalHold {
+			protected[claim.ClaimID] = true
+		}
+		for _, key := range claim.PostureKeys() {
+			if latest[key] < p.KeepLatest {
+				latest[key]++
+				protected[claim.ClaimID] = true
+			}
+		}
+	}
+
+	var evictions []Eviction
+	kept := make(map[requirementKey]int)
+	for _, claim := range sorted {
+		key := requirementKey{claim.TenantID(), claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef}
+		switch {
+		case protected[claim.ClaimID]:
+			kept[key]++
+		case p.MaxAge > 0 && now.Sub(claim.Timestamp) > p.MaxAge:
+			evictions = append(evictions, Eviction{Claim: claim, Reason: ReasonMaxAge})
+		case p.MaxPerRequirement > 0 && kept[key] >= p.MaxPerRequirement:
+			evictions = append(evictions, Eviction{Claim: claim, Reason: ReasonMaxPerRequirement})
+		default:
+			kept[key]++
+		}
+	}
+	return evictions
+}
+
+// Compactor periodically applies a retention policy to a store.
+type Compactor struct {
+	store    claims.Store
+	policy   Policy
+	interval time.Duration
+	onEvict  func(ctx context.Context, evictions []Eviction)
+}
+
+// NewCompactor creates a Compactor. onEvict is called after each compaction that evicted
+// claims and may be nil.
+func NewCompactor(store claims.Store, policy Policy, interval time.Duration, onEvict func(ctx context.Context, evictions []Eviction)) *Compactor {
+	if interval <= 0 {
+		interval = DefaultCompactionInterval
+	}
+	return &Compactor{
+		store:    store,
+		policy:   policy,
+		interval: interval,
+		onEvict:  onEvict,
+	}
+}
+
+// Compact evicts the claims selected by the policy. The store re-checks legal holds and
+// the current posture when deleting, so claims held or made current after selection
+// are kept and not reported as evicted.
+func (c *Compactor) Compact(ctx context.Context) ([]Eviction, error) {
+	if !c.policy.Enabled() {
+		return nil, nil
+	}
+	all, err := c.store.GetClaims()
+	if err != nil {
+		return nil, err
+	}
+	selected := c.policy.Select(all, time.Now())
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, 0, len(selected))
+	for _, eviction := range selected {
+		ids = append(ids, eviction.Claim.ClaimID)
+	}
+	deleted, err := c.store.Delete(ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evict claims: %w", err)
+	}
+	removed := make(map[string]bool, len(deleted))
+	for _, id := range deleted {
+		removed[id] = true
+	}
+	var evictions []Eviction
+	for _, eviction := range selected {
+		if removed[eviction.Claim.ClaimID] {
+			evictions = append(evictions, eviction)
+		}
+	}
+	if len(evictions) > 0 && c.onEvict != nil {
+		c.onEvict(ctx, evictions)
+	}
+	return evictions, nil
+}
+
+// Run compacts the store on every interval until the context is canceled.
+func (c *Compactor) Run(ctx context.Context) {
+	if !c.policy.Enabled() {
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			evictions, err := c.Compact(ctx)
+			if err != nil {
+				log.Printf("Claim store compaction failed: %v", err)
+				continue
+			}
+			if len(evictions) > 0 {
+				log.Printf("Evicted %d claims from the claim store", len(evictions))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
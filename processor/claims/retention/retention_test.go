@@ -0,0 +1,116 @@
+package retention
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+var now = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func newClaim(id string, age time.Duration, method string) claims.ConformanceClaim {
+	return claims.ConformanceClaim{
+		ClaimID:     id,
+		Timestamp:   now.Add(-age),
+		ResourceRef: "pod-a",
+		CatalogID:   "TEST-CAT",
+		Assessment: layer4.Assessment{
+			RequirementID: "CAT.T01.TR01",
+			Methods:       []layer4.AssessmentMethod{{Name: method}},
+		},
+	}
+}
+
+func evictedIDs(evictions []Eviction) []string {
+	var ids []string
+	for _, eviction := range evictions {
+		ids = append(ids, eviction.Claim.ClaimID+"="+eviction.Reason)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestSelect(t *testing.T) {
+	current := newClaim("current", 72*time.Hour, "Kyverno")
+	held := newClaim("held", 72*time.Hour, "OPA")
+	held.SupersededBy = "c0"
+	held.LegalHold = true
+	superseded := func(id string, age time.Duration) claims.ConformanceClaim {
+		claim := newClaim(id, age, "OPA")
+		claim.SupersededBy = "c0"
+		return claim
+	}
+	all := []claims.ConformanceClaim{
+		newClaim("c0", 0, "OPA"), current, held,
+		superseded("c1", time.Hour), superseded("c2", 2*time.Hour), superseded("c3", 48*time.Hour),
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []string
+	}{
+		{"disabled", Policy{}, nil},
+		{"max age", Policy{MaxAge: 24 * time.Hour}, []string{"c3=max_age"}},
+		{"max per requirement", Policy{MaxPerRequirement: 2}, []string{"c2=max_per_requirement", "c3=max_per_requirement"}},
+		{"keep latest", Policy{MaxPerRequirement: 1, KeepLatest: 2}, []string{"c2=max_per_requirement", "c3=max_per_requirement"}},
+		{"max age before count", Policy{MaxAge: 24 * time.Hour, MaxPerRequirement: 2}, []string{"c2=max_per_requirement", "c3=max_age"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evictedIDs(tt.policy.Select(all, now)); !slices.Equal(got, tt.want) {
+				t.Errorf("evictions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// holdingStore places a legal hold on a claim after the compactor reads its snapshot,
+// as an API request between selection and deletion would.
+type holdingStore struct {
+	*claims.MemoryStore
+	hold string
+}
+
+func (s *holdingStore) GetClaims() ([]claims.ConformanceClaim, error) {
+	all, err := s.MemoryStore.GetClaims()
+	if err != nil {
+		return nil, err
+	}
+	return all, s.MemoryStore.SetLegalHold(s.hold, true)
+}
+
+func TestCompactKeepsClaimsHeldAfterSelection(t *testing.T) {
+	store := &holdingStore{MemoryStore: claims.NewMemoryStore(), hold: "c1"}
+	for _, claim := range []claims.ConformanceClaim{
+		newClaim("c1", 72*time.Hour, "OPA"), newClaim("c2", 48*time.Hour, "OPA"), newClaim("c3", 0, "OPA"),
+	} {
+		if err := store.Add(claim); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var reported []Eviction
+	compactor := NewCompactor(store, Policy{MaxAge: 24 * time.Hour}, time.Hour, func(_ context.Context, evictions []Eviction) {
+		reported = evictions
+	})
+	evictions, err := compactor.Compact(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := evictedIDs(evictions); !slices.Equal(got, []string{"c2=max_age"}) {
+		t.Errorf("evictions = %v, want [c2=max_age]", got)
+	}
+	if got := evictedIDs(reported); !slices.Equal(got, []string{"c2=max_age"}) {
+		t.Errorf("reported evictions = %v, want [c2=max_age]", got)
+	}
+	if _, ok, _ := store.Get("c1"); !ok {
+		t.Error("claim held after selection was evicted")
+	}
+}
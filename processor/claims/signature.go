@@ -0,0 +1,73 @@
+package claims
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+	"github.com/in-toto/go-witness/dsse"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+// PayloadType is the DSSE payload type of a signed claim.
+const PayloadType = "application/vnd.shiny-journey.conformance-claim.v1+json"
+
+var (
+	// ErrUnsigned is returned when verifying a claim without a signature.
+	ErrUnsigned = errors.New("claim is not signed")
+	// ErrPayloadMismatch is returned when a claim no longer matches its signed payload.
+	ErrPayloadMismatch = errors.New("claim does not match its signed payload")
+)
+
+// CanonicalJSON returns the signed representation of the claim: the v1 wire format
+// canonicalized with JCS (RFC 8785). Store state that changes after the
+// claim is made, the supersession, legal hold, and signature, is left out.
+func CanonicalJSON(claim ConformanceClaim) ([]byte, error) {
+	claim.SupersededBy = ""
+	claim.LegalHold = false
+	claim.Signature = nil
+	data, err := json.Marshal(claim)
+	if err != nil {
+		return nil, err
+	}
+	return evidence.Canonicalize(data)
+}
+
+// Sign signs the canonical JSON of the claim into a DSSE envelope stored on the claim.
+func Sign(claim *ConformanceClaim, signers ...cryptoutil.Signer) error {
+	payload, err := CanonicalJSON(*claim)
+	if err != nil {
+		return fmt.Errorf("failed to encode claim %s for signing: %w", claim.ClaimID, err)
+	}
+	envelope, err := dsse.Sign(PayloadType, bytes.NewReader(payload), dsse.SignWithSigners(signers...))
+	if err != nil {
+		return fmt.Errorf("failed to sign claim %s: %w", claim.ClaimID, err)
+	}
+	claim.Signature = &envelope
+	return nil
+}
+
+// Verify checks that the claim is signed by one of the verifiers and has not changed
+// since it was signed.
+func Verify(claim ConformanceClaim, verifiers ...cryptoutil.Verifier) error {
+	if claim.Signature == nil {
+		return ErrUnsigned
+	}
+	if claim.Signature.PayloadType != PayloadType {
+		return fmt.Errorf("unexpected signature payload type %q", claim.Signature.PayloadType)
+	}
+	payload, err := CanonicalJSON(claim)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(payload, claim.Signature.Payload) {
+		return ErrPayloadMismatch
+	}
+	if _, err := claim.Signature.Verify(dsse.VerifyWithVerifiers(verifiers...)); err != nil {
+		return fmt.Errorf("invalid signature on claim %s: %w", claim.ClaimID, err)
+	}
+	return nil
+}
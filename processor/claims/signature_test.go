@@ -0,0 +1,116 @@
+package claims
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/in-toto/go-witness/cryptoutil"
+)
+
+// newKeyPair returns a signer and its verifier for a fresh ed25519 key.
+func newKeyPair(t *testing.T) (cryptoutil.Signer, cryptoutil.Verifier) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := cryptoutil.NewSigner(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := cryptoutil.NewVerifier(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer, verifier
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	claim := testClaim("c1", "OPA", StatusCompliant)
+	canonical, err := CanonicalJSON(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(canonical), `{"apiVersion":"`+APIVersion+`","assessment":{`) {
+		t.Errorf("canonical JSON does not sort keys: %s", canonical)
+	}
+
+	claim.SupersededBy = "c2"
+	claim.LegalHold = true
+	unsigned, err := CanonicalJSON(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(unsigned) != string(canonical) {
+		t.Errorf("store state changed the canonical JSON:\n got %s\nwant %s", unsigned, canonical)
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	signer, verifier := newKeyPair(t)
+	_, otherVerifier := newKeyPair(t)
+
+	signed := testClaim("c1", "OPA", StatusCompliant)
+	if err := Sign(&signed, signer); err != nil {
+		t.Fatal(err)
+	}
+	if signed.Signature == nil || signed.Signature.PayloadType != PayloadType {
+		t.Fatalf("signature = %+v, want a %s envelope", signed.Signature, PayloadType)
+	}
+
+	// Claims keep verifying after a round trip through the wire format.
+	data, err := json.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ConformanceClaim
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	superseded := signed
+	superseded.SupersededBy = "c2"
+	superseded.LegalHold = true
+	tampered := signed
+	tampered.Summary = "Resource 'pod-a' from OPA is allow."
+	wrongType := signed
+	envelope := *signed.Signature
+	envelope.PayloadType = "application/json"
+	wrongType.Signature = &envelope
+
+	tests := []struct {
+		name     string
+		claim    ConformanceClaim
+		verifier cryptoutil.Verifier
+		wantErr  error
+		errText  string
+	}{
+		{name: "signed", claim: signed, verifier: verifier},
+		{name: "decoded", claim: decoded, verifier: verifier},
+		{name: "store state changed", claim: superseded, verifier: verifier},
+		{name: "unsigned", claim: testClaim("c1", "OPA", StatusCompliant), verifier: verifier, wantErr: ErrUnsigned},
+		{name: "tampered", claim: tampered, verifier: verifier, wantErr: ErrPayloadMismatch},
+		{name: "wrong key", claim: signed, verifier: otherVerifier, errText: "invalid signature on claim c1"},
+		{name: "wrong payload type", claim: wrongType, verifier: verifier, errText: "unexpected signature payload type"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.claim, tt.verifier)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.errText != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("error = %v, want it to contain %q", err, tt.errText)
+				}
+			case err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package snapshot writes claim store contents to a portable, checksummed archive and
+// restores them into another store.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+// APIVersion is the version of the snapshot archive format.
+const APIVersion = "snapshots.shiny-journey.io/v1"
+
+const (
+	manifestFile = "manifest.json"
+	claimsFile   = "claims.jsonl"
+)
+
+// Manifest describes the contents of a snapshot archive.
+type Manifest struct {
+	APIVersion string    `json:"apiVersion"`
+	CreatedAt  time.Time `json:"createdAt"`
+	Claims     int       `json:"claims"`
+	// ConfigRevisions are the mapping ruleset revisions the claims were produced with.
+	ConfigRevisions []string `json:"configRevisions"`
+	// Digest is the sha256 digest of the claims file.
+	Digest string `json:"digest"`
+}
+
+// Write writes the claims to w as a gzipped tar archive holding the manifest and one
+// v1 wire format claim per line.
+func Write(w io.Writer, allClaims []claims.ConformanceClaim) (Manifest, error) {
+	sorted := append([]claims.ConformanceClaim(nil), allClaims...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].Timestamp.Equal(sorted[j].Timestamp) {
+			return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+		}
+		return sorted[i].ClaimID < sorted[j].ClaimID
+	})
+
+	var lines bytes.Buffer
+	revisions := make(map[string]struct{})
+	for _, claim := range sorted {
+		data, err := json.Marshal(claim)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("failed to encode claim %s: %w", claim.ClaimID, err)
+		}
+		lines.Write(data)
+		lines.WriteByte('\n')
+		if claim.ConfigRevision != "" {
+			revisions[claim.ConfigRevision] = struct{}{}
+		}
+	}
+
+	manifest := Manifest{
+		APIVersion:      APIVersion,
+		CreatedAt:       time.Now().UTC(),
+		Claims:          len(sorted),
+		ConfigRevisions: make([]string, 0, len(revisions)),
+		Digest:          digest(lines.Bytes()),
+	}
+	for revision := range revisions {
+		manifest.ConfigRevisions = append(manifest.ConfigRevisions, revision)
+	}
+	sort.Strings(manifest.ConfigRevisions)
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{manifestFile, manifestData},
+		{claimsFile, lines.Bytes()},
+	} {
+		header := &tar.Header{
+			Name:    file.name,
+			Mode:    0o644,
+			Size:    int64(len(file.data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return Manifest{}, err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return Manifest{}, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, gz.Close()
+}
+
+// Read reads a snapshot archive and verifies the claims against the manifest digest.
+func Read(r io.Reader) (Manifest, []claims.ConformanceClaim, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Manifest{}, nil, fmt.Errorf("not a snapshot archive: %w", err)
+	}
+	defer gz.Close()
+
+	var manifestData, claimsData []byte
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Manifest{}, nil, fmt.Errorf("failed to read snapshot archive: %w", err)
+		}
+		switch header.Name {
+		case manifestFile:
+			manifestData, err = io.ReadAll(tr)
+		case claimsFile:
+			claimsData, err = io.ReadAll(tr)
+		}
+		if err != nil {
+			return Manifest{}, nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+	}
+	if manifestData == nil || claimsData == nil {
+		return Manifest{}, nil, fmt.Errorf("snapshot archive must contain %s and %s", manifestFile, claimsFile)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return Manifest{}, nil, fmt.Errorf("invalid snapshot manifest: %w", err)
+	}
+	if manifest.APIVersion != APIVersion {
+		return Manifest{}, nil, fmt.Errorf("unsupported snapshot apiVersion %q", manifest.APIVersion)
+	}
+	if actual := digest(claimsData); actual != manifest.Digest {
+		return Manifest{}, nil, fmt.Errorf("snapshot digest mismatch: manifest has %s, claims are %s", manifest.Digest, actual)
+	}
+
+	var restored []claims.ConformanceClaim
+	scanner := bufio.NewScanner(bytes.NewReader(claimsData))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var claim claims.ConformanceClaim
+		if err := json.Unmarshal(scanner.Bytes(), &claim); err != nil {
+			return Manifest{}, nil, fmt.Errorf("invalid claim on line %d: %w", len(restored)+1, err)
+		}
+		restored = append(restored, claim)
+	}
+	if err := scanner.Err(); err != nil {
+		return Manifest{}, nil, err
+	}
+	if len(restored) != manifest.Claims {
+		return Manifest{}, nil, fmt.Errorf("snapshot manifest lists %d claims, archive has %d", manifest.Claims, len(restored))
+	}
+	return manifest, restored, nil
+}
+
+// Restore adds the claims to an empty store, oldest first, so the store rebuilds the
+// current posture exactly as the agent that took the snapshot did.
+func Restore(store claims.Store, restored []claims.ConformanceClaim) error {
+	existing, err := store.GetClaims()
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("cannot restore into a store that already holds %d claims", len(existing))
+	}
+	// Snapshots are written oldest first.
+	for _, claim := range restored {
+		if err := store.Add(claim); err != nil {
+			return fmt.Errorf("failed to restore claim %s: %w", claim.ClaimID, err)
+		}
+	}
+	return nil
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
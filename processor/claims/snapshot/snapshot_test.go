@@ -0,0 +1,170 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims"
+)
+
+var epoch = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// newClaim returns a claim on resource pod-a for requirement CAT.T01.TR01 from the source,
+// timestamped minutes after the epoch.
+func newClaim(id string, minutes int, source, status string) claims.ConformanceClaim {
+	method := layer4.AssessmentMethod{Name: source, Run: true, Result: &layer4.AssessmentResult{}}
+	setStatus(&method.Result.Status, status)
+	return claims.ConformanceClaim{
+		ClaimID:        id,
+		Timestamp:      epoch.Add(time.Duration(minutes) * time.Minute),
+		ResourceRef:    "pod-a",
+		CatalogID:      "TEST-CAT",
+		ControlID:      "CAT.T01",
+		ConfigRevision: "rev-" + source,
+		Assessment:     layer4.Assessment{RequirementID: "CAT.T01.TR01", Methods: []layer4.AssessmentMethod{method}},
+	}
+}
+
+func setStatus[T ~string](dst *T, status string) {
+	*dst = T(status)
+}
+
+// archive builds a gzipped tar archive holding the files in order.
+func archive(t *testing.T, files ...[2]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, file := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: file[0], Mode: 0o644, Size: int64(len(file[1]))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(file[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func currentIDs(t *testing.T, store claims.Store) []string {
+	t.Helper()
+	result, err := store.Query(claims.Query{CurrentOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, claim := range result.Claims {
+		got = append(got, claim.ClaimID)
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestSnapshotRestoresPosture(t *testing.T) {
+	source := claims.NewMemoryStore()
+	// c1 is superseded by c3; c2 from another source stays current.
+	for _, claim := range []claims.ConformanceClaim{
+		newClaim("c3", 2, "OPA", claims.StatusNotCompliant),
+		newClaim("c1", 0, "OPA", claims.StatusCompliant),
+		newClaim("c2", 1, "Kyverno", claims.StatusCompliant),
+	} {
+		if err := source.Add(claim); err != nil {
+			t.Fatal(err)
+		}
+	}
+	all, err := source.GetClaims()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	written, err := Write(&buf, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written.Claims != 3 || !slices.Equal(written.ConfigRevisions, []string{"rev-Kyverno", "rev-OPA"}) {
+		t.Errorf("manifest = %+v, want 3 claims from rev-Kyverno and rev-OPA", written)
+	}
+
+	manifest, restored, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Digest != written.Digest {
+		t.Errorf("digest = %s, want %s", manifest.Digest, written.Digest)
+	}
+	var order []string
+	for _, claim := range restored {
+		order = append(order, claim.ClaimID)
+	}
+	if !slices.Equal(order, []string{"c1", "c2", "c3"}) {
+		t.Errorf("restored order = %v, want oldest first", order)
+	}
+
+	target := claims.NewMemoryStore()
+	if err := Restore(target, restored); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := currentIDs(t, target), currentIDs(t, source); !slices.Equal(got, want) || !slices.Equal(got, []string{"c2", "c3"}) {
+		t.Errorf("restored current claims = %v, want %v", got, want)
+	}
+	if err := Restore(target, restored); err == nil || !strings.Contains(err.Error(), "already holds 3 claims") {
+		t.Errorf("restore into a populated store: %v", err)
+	}
+}
+
+func TestReadRejectsInvalidArchives(t *testing.T) {
+	var buf bytes.Buffer
+	manifest, err := Write(&buf, []claims.ConformanceClaim{newClaim("c1", 0, "OPA", claims.StatusCompliant)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := `{"apiVersion":"` + claims.APIVersion + `","kind":"` + claims.Kind + `","claimId":"c1"}` + "\n"
+	manifestFor := func(claimsData string, count int) string {
+		return fmt.Sprintf(`{"apiVersion":%q,"claims":%d,"digest":%q}`, APIVersion, count, digest([]byte(claimsData)))
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"not gzip", []byte("plain text"), "not a snapshot archive"},
+		{"missing claims", archive(t, [2]string{manifestFile, manifestFor("", 0)}), "must contain"},
+		{"tampered claims", archive(t,
+			[2]string{manifestFile, `{"apiVersion":"` + APIVersion + `","claims":1,"digest":"` + manifest.Digest + `"}`},
+			[2]string{claimsFile, line}), "digest mismatch"},
+		{"unknown version", archive(t,
+			[2]string{manifestFile, `{"apiVersion":"snapshots.shiny-journey.io/v9"}`},
+			[2]string{claimsFile, ""}), "unsupported snapshot apiVersion"},
+		{"claim count", archive(t,
+			[2]string{manifestFile, manifestFor(line, 2)},
+			[2]string{claimsFile, line}), "lists 2 claims, archive has 1"},
+		{"invalid claim", archive(t,
+			[2]string{manifestFile, manifestFor("[]\n", 1)},
+			[2]string{claimsFile, "[]\n"}), "invalid claim on line 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Read(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package claims
+
+// Assessment statuses reported on claim methods.
+const (
+	// StatusCompliant indicates the resource satisfies the requirement.
+	StatusCompliant = "COMPLIANT"
+	// StatusNotCompliant indicates the resource violates the requirement.
+	StatusNotCompliant = "NOT_COMPLIANT"
+	// StatusNotApplicable indicates the requirement does not apply to the resource.
+	StatusNotApplicable = "NOT_APPLICABLE"
+	// StatusNeedsReview indicates the method reported a finding that requires
+	// human review, such as a Kyverno warn or audit result.
+	StatusNeedsReview = "NEEDS_REVIEW"
+	// StatusUnknown indicates the method decision could not be interpreted.
+	StatusUnknown = "UNKNOWN"
+	// StatusError indicates the method failed to evaluate the resource.
+	StatusError = "ERROR"
+	// StatusWaived indicates a failure was accepted through an exception.
+	StatusWaived = "WAIVED"
+	// StatusStale indicates no evidence arrived within the expected cadence, so the
+	// last reported result can no longer be relied on.
+	StatusStale = "STALE"
+)
+
+// Statuses lists every assessment status in a stable order.
+var Statuses = []string{
+	StatusCompliant,
+	StatusNotCompliant,
+	StatusNotApplicable,
+	StatusNeedsReview,
+	StatusUnknown,
+	StatusError,
+	StatusWaived,
+	StatusStale,
+}
+
+// IsUnresolved reports whether the status is neither a pass nor a failure and
+// needs follow-up before a verdict can be reached.
+func IsUnresolved(status string) bool {
+	switch status {
+	case StatusNeedsReview, StatusUnknown, StatusError, StatusStale:
+		return true
+	default:
+		return false
+	}
+}
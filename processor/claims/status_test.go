@@ -0,0 +1,59 @@
+package claims
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jpower432/shiny-journey/processor/claims/evidence"
+)
+
+func TestSourceDecisionStatus(t *testing.T) {
+	tests := []struct {
+		source      string
+		decision    string
+		status      string
+		description string
+	}{
+		{"OPA", "allow", StatusCompliant, "OPA allowed access"},
+		{"OPA", "deny", StatusNotCompliant, "OPA denied access"},
+		{"OPA", "error", StatusError, "OPA failed to evaluate"},
+		{"OPA", "partial", StatusUnknown, "unrecognized decision 'partial'"},
+		{"Kyverno", "mutate", StatusCompliant, "Kyverno mutated resource"},
+		{"Kyverno", "pass", StatusCompliant, "Kyverno validated resource"},
+		{"Kyverno", "deny", StatusNotCompliant, "Kyverno denied resource"},
+		{"Kyverno", "fail", StatusNotCompliant, "Kyverno denied resource"},
+		{"Kyverno", "warn", StatusNeedsReview, "reported a warn result"},
+		{"Kyverno", "audit", StatusNeedsReview, "reported a audit result"},
+		{"Kyverno", "skip", StatusNotApplicable, "Kyverno skipped resource"},
+		{"Kyverno", "error", StatusError, "Kyverno failed to evaluate"},
+		{"Kyverno", "", StatusUnknown, "unrecognized decision ''"},
+		{"OpenSCAP", "compliant", StatusCompliant, "reported compliant"},
+		{"OpenSCAP", "non_compliant", StatusNotCompliant, "reported non-compliant"},
+		{"OpenSCAP", "notapplicable", StatusNotApplicable, "as not applicable"},
+		{"OpenSCAP", "informational", StatusNeedsReview, "require manual review"},
+		{"OpenSCAP", "notchecked", StatusNeedsReview, "require manual review"},
+		{"OpenSCAP", "error", StatusError, "failed against profile"},
+		{"OpenSCAP", "fixed", StatusUnknown, "unrecognized result 'fixed'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source+"/"+tt.decision, func(t *testing.T) {
+			rawEv := evidence.RawEvidence{Metadata: evidence.Metadata{Source: tt.source, PolicyID: "p1", Decision: tt.decision}}
+			method := sourceToMethod[tt.source](rawEv)
+			if got := resultStatus(method.Result); got != tt.status {
+				t.Errorf("status = %s, want %s", got, tt.status)
+			}
+			if !strings.Contains(method.Description, tt.description) {
+				t.Errorf("description = %q, want it to contain %q", method.Description, tt.description)
+			}
+		})
+	}
+}
+
+func TestIsUnresolved(t *testing.T) {
+	unresolved := map[string]bool{StatusNeedsReview: true, StatusUnknown: true, StatusError: true, StatusStale: true}
+	for _, status := range Statuses {
+		if got := IsUnresolved(status); got != unresolved[status] {
+			t.Errorf("IsUnresolved(%s) = %t, want %t", status, got, unresolved[status])
+		}
+	}
+}
@@ -1,28 +1,184 @@
 package claims
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
 
-type Store struct {
-	mu     sync.RWMutex
-	claims map[string]ConformanceClaim
+// ErrNotFound is returned when a claim is not in the store.
+var ErrNotFound = errors.New("claim not found")
+
+// Store persists conformance claims.
+type Store interface {
+	// Add stores a claim, replacing any stored claim with the same ID. Older claims for
+	// the same posture keys are marked as superseded.
+	Add(claim ConformanceClaim) error
+	// Get returns the claim with the given ID, if it is stored.
+	Get(claimID string) (ConformanceClaim, bool, error)
+	// GetClaims returns all stored claims.
+	GetClaims() ([]ConformanceClaim, error)
+	// Current returns the claims that make up the current posture.
+	Current() ([]ConformanceClaim, error)
+	// Query returns a sorted page of the claims matching the query.
+	Query(q Query) (QueryResult, error)
+	// Delete removes the claims with the given IDs and returns the IDs it removed. Claims
+	// that are current or under legal hold when the delete runs are kept, even if they
+	// were not when the IDs were selected.
+	Delete(claimIDs ...string) ([]string, error)
+	// SetLegalHold places or releases a legal hold on a stored claim.
+	SetLegalHold(claimID string, hold bool) error
+	// Watch streams the events after the given revision, then every new event, until the
+	// context is canceled. A zero revision streams new events only. A revision outside the
+	// retained history returns an error wrapping ErrResync.
+	Watch(ctx context.Context, afterRevision uint64) (<-chan Event, error)
+	// Close releases any resources held by the store.
+	Close() error
 }
 
-func NewStore() *Store {
-	return &Store{
-		claims: make(map[string]ConformanceClaim),
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore keeps claims in memory. Claims are lost when the process exits.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	claims  map[string]ConformanceClaim
+	current map[PostureKey]string
+	events  eventLog
+	feed    Feed
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		claims:  make(map[string]ConformanceClaim),
+		current: make(map[PostureKey]string),
 	}
 }
 
-func (s *Store) Add(claim ConformanceClaim) {
+func (s *MemoryStore) Add(claim ConformanceClaim) error {
 	s.mu.Lock()
+	defer s.mu.Unlock()
+	superseded, err := UpdatePosture(memoryPosture{s}, &claim)
+	if err != nil {
+		return err
+	}
 	s.claims[claim.ClaimID] = claim
-	s.mu.Unlock()
+	events := []Event{s.events.append(EventAdded, claim)}
+	for _, old := range superseded {
+		s.claims[old.ClaimID] = old
+		events = append(events, s.events.append(EventSuperseded, old))
+	}
+	s.feed.Publish(events...)
+	return nil
+}
+
+func (s *MemoryStore) Get(claimID string) (ConformanceClaim, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	claim, ok := s.claims[claimID]
+	return claim, ok, nil
 }
 
-func (s *Store) GetClaims() []ConformanceClaim {
+func (s *MemoryStore) GetClaims() ([]ConformanceClaim, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	claims := make([]ConformanceClaim, 0, len(s.claims))
 	for _, claim := range s.claims {
 		claims = append(claims, claim)
 	}
-	return claims
+	return claims, nil
+}
+
+func (s *MemoryStore) Current() ([]ConformanceClaim, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var claims []ConformanceClaim
+	for _, claim := range s.claims {
+		if claim.Current() {
+			claims = append(claims, claim)
+		}
+	}
+	return claims, nil
+}
+
+func (s *MemoryStore) Query(q Query) (QueryResult, error) {
+	claims, err := s.GetClaims()
+	if err != nil {
+		return QueryResult{}, err
+	}
+	return Page(q, claims)
+}
+
+func (s *MemoryStore) Delete(claimIDs ...string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted []string
+	for _, claimID := range claimIDs {
+		claim, ok := s.claims[claimID]
+		if !ok || claim.Current() || claim.LegalHold {
+			continue
+		}
+		delete(s.claims, claimID)
+		deleted = append(deleted, claimID)
+		s.feed.Publish(s.events.append(EventExpired, claim))
+	}
+	return deleted, nil
+}
+
+func (s *MemoryStore) SetLegalHold(claimID string, hold bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	claim, ok := s.claims[claimID]
+	if !ok {
+		return fmt.Errorf("claim %s: %w", claimID, ErrNotFound)
+	}
+	claim.LegalHold = hold
+	s.claims[claimID] = claim
+	return nil
+}
+
+func (s *MemoryStore) Watch(ctx context.Context, afterRevision uint64) (<-chan Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	replay, err := s.events.after(afterRevision)
+	if err != nil {
+		return nil, err
+	}
+	return s.feed.Subscribe(ctx, replay), nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// memoryPosture is the PostureIndex of a MemoryStore. Callers hold the store lock.
+type memoryPosture struct {
+	s *MemoryStore
+}
+
+func (p memoryPosture) CurrentID(key PostureKey) (string, error) {
+	return p.s.current[key], nil
+}
+
+func (p memoryPosture) SetCurrent(key PostureKey, claimID string) error {
+	p.s.current[key] = claimID
+	return nil
+}
+
+func (p memoryPosture) Claim(claimID string) (ConformanceClaim, bool, error) {
+	claim, ok := p.s.claims[claimID]
+	return claim, ok, nil
+}
+
+// InRange reports whether t falls within [start, end). A zero start or end leaves
+// that side of the range open.
+func InRange(t, start, end time.Time) bool {
+	if !start.IsZero() && t.Before(start) {
+		return false
+	}
+	if !end.IsZero() && !t.Before(end) {
+		return false
+	}
+	return true
 }
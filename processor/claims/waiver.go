@@ -0,0 +1,45 @@
+package claims
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/revanite-io/sci/layer4"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+// ApplyWaivers returns the claims with every NOT_COMPLIANT method covered by an active
+// waiver in the ruleset reported as WAIVED. The given claims are not modified, so the
+// stored record keeps the original verdict.
+func ApplyWaivers(current []ConformanceClaim, ruleset *mapping.Ruleset, now time.Time) []ConformanceClaim {
+	waived := make([]ConformanceClaim, 0, len(current))
+	for _, claim := range current {
+		waiver, ok := ruleset.Waiver(claim.TenantID(), claim.CatalogID, claim.Assessment.RequirementID, claim.ResourceRef, now)
+		if !ok {
+			waived = append(waived, claim)
+			continue
+		}
+		var methods []layer4.AssessmentMethod
+		for i, method := range claim.Assessment.Methods {
+			if method.Result == nil || string(method.Result.Status) != StatusNotCompliant {
+				continue
+			}
+			if methods == nil {
+				methods = append([]layer4.AssessmentMethod(nil), claim.Assessment.Methods...)
+			}
+			accepted := method
+			accepted.Result = &layer4.AssessmentResult{}
+			setStatus(&accepted.Result.Status, StatusWaived)
+			accepted.Description = strings.TrimSpace(fmt.Sprintf("%s result waived by %s until %s, approved by %s: %s. %s",
+				StatusNotCompliant, waiver.ID, waiver.Expires.Format(time.RFC3339), waiver.Approver, waiver.Justification, method.Description))
+			methods[i] = accepted
+		}
+		if methods != nil {
+			claim.Assessment.Methods = methods
+		}
+		waived = append(waived, claim)
+	}
+	return waived
+}
@@ -0,0 +1,61 @@
+package claims
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jpower432/shiny-journey/processor/claims/mapping"
+)
+
+func TestApplyWaivers(t *testing.T) {
+	ruleset := mapping.Default()
+	ruleset.Waivers = []mapping.Waiver{{
+		ID:            "WVR-1",
+		CatalogID:     "TEST-CAT",
+		RequirementID: "CAT.T01.TR01",
+		Resource:      "pod-*",
+		Justification: "Accepted until the fix ships.",
+		Approver:      "security-team",
+		Expires:       testTime.Add(24 * time.Hour),
+	}}
+
+	failing := testClaim("c1", "OPA", StatusNotCompliant)
+	failing.Assessment.Methods = append(failing.Assessment.Methods, testClaim("c1", "Kyverno", StatusCompliant).Assessment.Methods...)
+	otherResource := testClaim("c2", "OPA", StatusNotCompliant)
+	otherResource.ResourceRef = "node-a"
+	needsReview := testClaim("c3", "OPA", StatusNeedsReview)
+	current := []ConformanceClaim{failing, otherResource, needsReview}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want [][]string
+	}{
+		{"active", testTime, [][]string{{StatusWaived, StatusCompliant}, {StatusNotCompliant}, {StatusNeedsReview}}},
+		{"expired", testTime.Add(48 * time.Hour), [][]string{{StatusNotCompliant, StatusCompliant}, {StatusNotCompliant}, {StatusNeedsReview}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			waived := ApplyWaivers(current, ruleset, tt.now)
+			for i, claim := range waived {
+				var got []string
+				for _, method := range claim.Assessment.Methods {
+					got = append(got, resultStatus(method.Result))
+				}
+				if strings.Join(got, ",") != strings.Join(tt.want[i], ",") {
+					t.Errorf("claim %s statuses = %v, want %v", claim.ClaimID, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	waived := ApplyWaivers(current, ruleset, testTime)
+	if description := waived[0].Assessment.Methods[0].Description; !strings.Contains(description, "waived by WVR-1") ||
+		!strings.Contains(description, "approved by security-team") {
+		t.Errorf("waived description = %q", description)
+	}
+	if statusOf(current[0]) != StatusNotCompliant {
+		t.Errorf("ApplyWaivers modified the stored claim: status %s", statusOf(current[0]))
+	}
+}
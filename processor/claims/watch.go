@@ -0,0 +1,188 @@
+package claims
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// EventType is the kind of change made to a stored claim.
+type EventType string
+
+const (
+	// EventAdded is sent when a claim is stored.
+	EventAdded EventType = "ADDED"
+	// EventSuperseded is sent when a newer claim supersedes a stored claim.
+	EventSuperseded EventType = "SUPERSEDED"
+	// EventExpired is sent when a claim is removed from the store, e.g. by retention.
+	EventExpired EventType = "EXPIRED"
+)
+
+// Event is a change to a stored claim. Revisions increase by one with every event.
+type Event struct {
+	Revision uint64           `json:"revision"`
+	Type     EventType        `json:"type"`
+	Claim    ConformanceClaim `json:"claim"`
+}
+
+// MaxEventHistory is the number of events stores keep for resuming watches.
+const MaxEventHistory = 10000
+
+// maxPendingEvents is how far a watcher may fall behind before it is disconnected.
+const maxPendingEvents = 1024
+
+// ErrResync is wrapped by the errors returned when a watch cannot resume from the requested
+// revision. The watcher must re-read the store and watch again from revision 0.
+var ErrResync = errors.New("event history is gone, resync and watch from revision 0")
+
+// ErrRevisionCompacted is returned when a watch resumes from a revision older than the
+// event history kept by the store.
+var ErrRevisionCompacted = fmt.Errorf("%w: revision is no longer in the event history", ErrResync)
+
+// ErrRevisionAhead is returned when a watch resumes from a revision the store has not
+// reached, such as after a MemoryStore restart resets revisions to 0.
+var ErrRevisionAhead = fmt.Errorf("%w: revision is ahead of the event history", ErrResync)
+
+// Feed delivers store events to watchers. Stores must publish events in revision order
+// and must not publish concurrently with Subscribe, so a watcher neither misses nor
+// repeats an event between its replay and the live stream.
+type Feed struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// Subscribe returns a channel that receives the replayed events followed by every
+// published event until the context is canceled. The channel is closed when the context
+// is canceled or the watcher falls too far behind; watchers resume from the last
+// revision they received.
+func (f *Feed) Subscribe(ctx context.Context, replay []Event) <-chan Event {
+	sub := &subscriber{
+		queue:  replay,
+		notify: make(chan struct{}, 1),
+		out:    make(chan Event),
+	}
+	f.mu.Lock()
+	if f.subscribers == nil {
+		f.subscribers = make(map[*subscriber]struct{})
+	}
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+
+	sub.wake()
+	go func() {
+		sub.run(ctx)
+		f.mu.Lock()
+		delete(f.subscribers, sub)
+		f.mu.Unlock()
+	}()
+	return sub.out
+}
+
+// Publish queues events for every watcher without blocking.
+func (f *Feed) Publish(events ...Event) {
+	if len(events) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for sub := range f.subscribers {
+		if !sub.push(events) {
+			delete(f.subscribers, sub)
+		}
+	}
+}
+
+type subscriber struct {
+	mu      sync.Mutex
+	queue   []Event
+	dropped bool
+	notify  chan struct{}
+	out     chan Event
+}
+
+// push queues events, or marks the subscriber as dropped if it is too far behind.
+func (s *subscriber) push(events []Event) bool {
+	s.mu.Lock()
+	if len(s.queue)+len(events) > maxPendingEvents {
+		s.dropped = true
+	} else {
+		s.queue = append(s.queue, events...)
+	}
+	s.mu.Unlock()
+	s.wake()
+	return !s.dropped
+}
+
+func (s *subscriber) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *subscriber) run(ctx context.Context) {
+	defer close(s.out)
+	for {
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return
+		}
+		s.mu.Lock()
+		events, dropped := s.queue, s.dropped
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, event := range events {
+			select {
+			case s.out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if dropped {
+			return
+		}
+	}
+}
+
+// eventLog is an in-memory event history used by MemoryStore. It keeps the last
+// MaxEventHistory events, trimming in batches so appends do not copy the history.
+type eventLog struct {
+	revision uint64
+	events   []Event
+}
+
+func (l *eventLog) append(eventType EventType, claim ConformanceClaim) Event {
+	l.revision++
+	event := Event{Revision: l.revision, Type: eventType, Claim: claim}
+	l.events = append(l.events, event)
+	if n := len(l.events); n > 2*MaxEventHistory {
+		copy(l.events, l.events[n-MaxEventHistory:])
+		clear(l.events[MaxEventHistory:n])
+		l.events = l.events[:MaxEventHistory]
+	}
+	return event
+}
+
+// history returns the retained events, oldest first.
+func (l *eventLog) history() []Event {
+	return l.events[max(0, len(l.events)-MaxEventHistory):]
+}
+
+// after returns the events after the revision. A zero revision replays nothing.
+func (l *eventLog) after(revision uint64) ([]Event, error) {
+	if revision > l.revision {
+		return nil, ErrRevisionAhead
+	}
+	if revision == 0 || revision == l.revision {
+		return nil, nil
+	}
+	history := l.history()
+	if len(history) == 0 || history[0].Revision > revision+1 {
+		return nil, ErrRevisionCompacted
+	}
+	start := revision + 1 - history[0].Revision
+	return append([]Event(nil), history[start:]...), nil
+}
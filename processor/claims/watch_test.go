@@ -0,0 +1,97 @@
+package claims
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEventLogAfter(t *testing.T) {
+	var log eventLog
+	for i := 0; i < MaxEventHistory+2; i++ {
+		log.append(EventAdded, testClaim("c", "OPA", StatusCompliant))
+	}
+	tests := []struct {
+		name     string
+		revision uint64
+		want     int
+		wantErr  error
+	}{
+		{"zero replays nothing", 0, 0, nil},
+		{"current replays nothing", MaxEventHistory + 2, 0, nil},
+		{"oldest retained", 2, MaxEventHistory, nil},
+		{"compacted", 1, 0, ErrRevisionCompacted},
+		{"ahead", MaxEventHistory + 3, 0, ErrRevisionAhead},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := log.after(tt.revision)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, ErrResync) {
+				t.Errorf("error %v does not wrap ErrResync", err)
+			}
+			if len(events) != tt.want {
+				t.Errorf("got %d events, want %d", len(events), tt.want)
+			}
+		})
+	}
+}
+
+func TestEventLogTrimsInBatches(t *testing.T) {
+	var log eventLog
+	for i := 0; i < 2*MaxEventHistory; i++ {
+		log.append(EventAdded, testClaim("c", "OPA", StatusCompliant))
+	}
+	if len(log.events) != 2*MaxEventHistory {
+		t.Fatalf("kept %d events before trimming, want %d", len(log.events), 2*MaxEventHistory)
+	}
+	log.append(EventAdded, testClaim("c", "OPA", StatusCompliant))
+	if len(log.events) != MaxEventHistory {
+		t.Fatalf("kept %d events after trimming, want %d", len(log.events), MaxEventHistory)
+	}
+
+	last := uint64(2*MaxEventHistory + 1)
+	events, err := log.after(last - MaxEventHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != MaxEventHistory || events[0].Revision != last-MaxEventHistory+1 || events[len(events)-1].Revision != last {
+		t.Errorf("replayed %d events from %d to %d", len(events), events[0].Revision, events[len(events)-1].Revision)
+	}
+	if _, err := log.after(last - MaxEventHistory - 1); !errors.Is(err, ErrRevisionCompacted) {
+		t.Errorf("error = %v, want ErrRevisionCompacted", err)
+	}
+}
+
+func TestMemoryStoreWatchAfterRestart(t *testing.T) {
+	store := NewMemoryStore()
+	if err := store.Add(testClaim("c1", "OPA", StatusCompliant)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A watcher that saw revision 5 before a restart must resync.
+	if _, err := store.Watch(ctx, 5); !errors.Is(err, ErrResync) {
+		t.Fatalf("watch ahead of the store: %v, want ErrResync", err)
+	}
+
+	events, err := store.Watch(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(testClaim("c2", "OPA", StatusCompliant)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-events:
+		if event.Claim.ClaimID != "c2" {
+			t.Errorf("event for %s, want c2", event.Claim.ClaimID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event for c2")
+	}
+}
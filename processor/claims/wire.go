@@ -0,0 +1,217 @@
+package claims
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/in-toto/go-witness/dsse"
+	"github.com/invopop/jsonschema"
+	"github.com/revanite-io/sci/layer4"
+)
+
+const (
+	// APIVersion is the version of the claim wire format.
+	APIVersion = "claims.shiny-journey.io/v1"
+	// Kind identifies a ConformanceClaim document.
+	Kind = "ConformanceClaim"
+	// SchemaID is the identifier of the published JSON Schema for the claim wire format.
+	SchemaID = "https://github.com/jpower432/shiny-journey/docs/schemas/conformance-claim.v1.json"
+)
+
+// claimV1 is the v1 wire representation of a ConformanceClaim.
+type claimV1 struct {
+	APIVersion      string             `json:"apiVersion" jsonschema:"enum=claims.shiny-journey.io/v1"`
+	Kind            string             `json:"kind" jsonschema:"enum=ConformanceClaim"`
+	ClaimID         string             `json:"claimId"`
+	Timestamp       time.Time          `json:"timestamp"`
+	ResourceRef     string             `json:"resourceRef"`
+	RawEvidenceRef  string             `json:"rawEvidenceRef"`
+	Summary         string             `json:"summary"`
+	Assessment      assessmentV1       `json:"assessment"`
+	CatalogID       string             `json:"catalogId"`
+	ControlID       string             `json:"controlId"`
+	Tenant          string             `json:"tenant,omitempty"`
+	ConfigRevision  string             `json:"configRevision,omitempty"`
+	DerivedFrom     string             `json:"derivedFrom,omitempty"`
+	MappingStrength int                `json:"mappingStrength,omitempty" jsonschema:"minimum=1,maximum=10"`
+	SupersededBy    string             `json:"supersededBy,omitempty"`
+	LegalHold       bool               `json:"legalHold,omitempty"`
+	Manual          *ManualAttestation `json:"manual,omitempty"`
+	Provenance      *Provenance        `json:"provenance,omitempty"`
+	Signature       *dsse.Envelope     `json:"signature,omitempty"`
+}
+
+type assessmentV1 struct {
+	RequirementID string     `json:"requirementId"`
+	Methods       []methodV1 `json:"methods"`
+}
+
+type methodV1 struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Run         bool      `json:"run"`
+	Result      *resultV1 `json:"result,omitempty"`
+}
+
+type resultV1 struct {
+	Status string `json:"status" jsonschema:"enum=COMPLIANT,enum=NOT_COMPLIANT,enum=NOT_APPLICABLE,enum=NEEDS_REVIEW,enum=UNKNOWN,enum=ERROR,enum=WAIVED,enum=STALE"`
+}
+
+// legacyClaim is the unversioned shape emitted before the v1 wire format, which
+// is still found in existing logs.
+type legacyClaim struct {
+	ClaimID         string    `json:"clamId"`
+	Timestamp       time.Time `json:"timestamp"`
+	ResourceRef     string    `json:"resourceRef"`
+	Summary         string    `json:"summary"`
+	CatalogID       string    `json:"catalogId"`
+	ControlID       string    `json:"controlId"`
+	ConfigRevision  string    `json:"configRevision"`
+	DerivedFrom     string    `json:"derivedFrom"`
+	MappingStrength int       `json:"mappingStrength"`
+	Assessment      struct {
+		RequirementID string     `json:"requirement_id"`
+		Methods       []methodV1 `json:"methods"`
+	} `json:"assessment"`
+}
+
+// MarshalJSON encodes the claim in the v1 wire format.
+func (c ConformanceClaim) MarshalJSON() ([]byte, error) {
+	wire := claimV1{
+		APIVersion:      APIVersion,
+		Kind:            Kind,
+		ClaimID:         c.ClaimID,
+		Timestamp:       c.Timestamp,
+		ResourceRef:     c.ResourceRef,
+		RawEvidenceRef:  c.RawEvidenceRef,
+		Summary:         c.Summary,
+		CatalogID:       c.CatalogID,
+		ControlID:       c.ControlID,
+		Tenant:          c.Tenant,
+		ConfigRevision:  c.ConfigRevision,
+		DerivedFrom:     c.DerivedFrom,
+		MappingStrength: c.MappingStrength,
+		SupersededBy:    c.SupersededBy,
+		LegalHold:       c.LegalHold,
+		Manual:          c.Manual,
+		Provenance:      c.Provenance,
+		Signature:       c.Signature,
+		Assessment: assessmentV1{
+			RequirementID: c.Assessment.RequirementID,
+			Methods:       make([]methodV1, 0, len(c.Assessment.Methods)),
+		},
+	}
+	for _, method := range c.Assessment.Methods {
+		wireMethod := methodV1{
+			Name:        method.Name,
+			Description: method.Description,
+			Run:         method.Run,
+		}
+		if method.Result != nil {
+			wireMethod.Result = &resultV1{Status: string(method.Result.Status)}
+		}
+		wire.Assessment.Methods = append(wire.Assessment.Methods, wireMethod)
+	}
+	return json.Marshal(wire)
+}
+
+// UnmarshalJSON decodes a claim in the v1 wire format or the legacy unversioned shape.
+func (c *ConformanceClaim) UnmarshalJSON(data []byte) error {
+	var header struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	switch header.APIVersion {
+	case "":
+		return c.unmarshalLegacy(data)
+	case APIVersion:
+		if header.Kind != Kind {
+			return fmt.Errorf("unsupported kind %q for %s", header.Kind, APIVersion)
+		}
+	default:
+		return fmt.Errorf("unsupported claim apiVersion %q", header.APIVersion)
+	}
+
+	var wire claimV1
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*c = ConformanceClaim{
+		ClaimID:         wire.ClaimID,
+		Timestamp:       wire.Timestamp,
+		ResourceRef:     wire.ResourceRef,
+		RawEvidenceRef:  wire.RawEvidenceRef,
+		Summary:         wire.Summary,
+		CatalogID:       wire.CatalogID,
+		ControlID:       wire.ControlID,
+		Tenant:          wire.Tenant,
+		ConfigRevision:  wire.ConfigRevision,
+		DerivedFrom:     wire.DerivedFrom,
+		MappingStrength: wire.MappingStrength,
+		SupersededBy:    wire.SupersededBy,
+		LegalHold:       wire.LegalHold,
+		Manual:          wire.Manual,
+		Provenance:      wire.Provenance,
+		Signature:       wire.Signature,
+		Assessment:      newAssessment(wire.Assessment.RequirementID, wire.Assessment.Methods),
+	}
+	return nil
+}
+
+// unmarshalLegacy decodes the unversioned shape. It never carried the raw evidence reference.
+func (c *ConformanceClaim) unmarshalLegacy(data []byte) error {
+	var legacy legacyClaim
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*c = ConformanceClaim{
+		ClaimID:         legacy.ClaimID,
+		Timestamp:       legacy.Timestamp,
+		ResourceRef:     legacy.ResourceRef,
+		Summary:         legacy.Summary,
+		CatalogID:       legacy.CatalogID,
+		ControlID:       legacy.ControlID,
+		ConfigRevision:  legacy.ConfigRevision,
+		DerivedFrom:     legacy.DerivedFrom,
+		MappingStrength: legacy.MappingStrength,
+		Assessment:      newAssessment(legacy.Assessment.RequirementID, legacy.Assessment.Methods),
+	}
+	return nil
+}
+
+func newAssessment(requirementID string, methods []methodV1) layer4.Assessment {
+	assessment := layer4.Assessment{RequirementID: requirementID}
+	for _, wireMethod := range methods {
+		method := layer4.AssessmentMethod{
+			Name:        wireMethod.Name,
+			Description: wireMethod.Description,
+			Run:         wireMethod.Run,
+		}
+		if wireMethod.Result != nil {
+			method.Result = &layer4.AssessmentResult{}
+			setStatus(&method.Result.Status, wireMethod.Result.Status)
+		}
+		assessment.Methods = append(assessment.Methods, method)
+	}
+	return assessment
+}
+
+// setStatus assigns a status string to a layer4 result status, whose type is defined by the sci module.
+func setStatus[T ~string](dst *T, status string) {
+	*dst = T(status)
+}
+
+// Schema returns the JSON Schema of the claim wire format.
+func Schema() *jsonschema.Schema {
+	reflector := jsonschema.Reflector{DoNotReference: true}
+	schema := reflector.Reflect(&claimV1{})
+	schema.ID = SchemaID
+	schema.Title = Kind
+	schema.Description = fmt.Sprintf("A conformance claim in the %s wire format.", APIVersion)
+	return schema
+}
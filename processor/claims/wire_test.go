@@ -0,0 +1,157 @@
+package claims
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWireRoundTrip(t *testing.T) {
+	derived := testClaim("c2", "Kyverno", StatusNotCompliant)
+	derived.DerivedFrom = "c1"
+	derived.MappingStrength = 7
+	derived.SupersededBy = "c3"
+
+	held := testClaim("c4", "OpenSCAP", StatusNeedsReview)
+	held.Tenant = "team-a"
+	held.LegalHold = true
+	held.Provenance = &Provenance{
+		EvidenceDigest:  held.RawEvidenceRef,
+		EvidenceDigests: map[string]string{"sha256": strings.TrimPrefix(held.RawEvidenceRef, "sha256:")},
+		Mapping:         "rule OpenSCAP/xccdf",
+		MappingRevision: "def456",
+		CatalogVersion:  "0.1.0",
+		AgentVersion:    "v1.2.3",
+	}
+
+	manual := testClaim("c5", MethodManual, StatusCompliant)
+	manual.Manual = &ManualAttestation{
+		CatalogID:     "TEST-CAT",
+		RequirementID: "CAT.T01.TR01",
+		ResourceRef:   "pod-a",
+		Status:        StatusCompliant,
+		Statement:     "Reviewed the procedure.",
+		Attester:      Attester{Name: "Jane Doe", Email: "jane@example.com"},
+		ValidFrom:     testTime.Add(-time.Hour),
+		ValidUntil:    testTime.Add(24 * time.Hour),
+		KeyID:         "key-1",
+	}
+
+	noResult := testClaim("c6", "OPA", StatusCompliant)
+	noResult.Assessment.Methods[0].Result = nil
+
+	tests := []struct {
+		name  string
+		claim ConformanceClaim
+	}{
+		{"native", testClaim("c1", "OPA", StatusCompliant)},
+		{"derived", derived},
+		{"tenant with provenance", held},
+		{"manual", manual},
+		{"method without result", noResult},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.claim)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var header struct {
+				APIVersion string `json:"apiVersion"`
+				Kind       string `json:"kind"`
+			}
+			if err := json.Unmarshal(data, &header); err != nil {
+				t.Fatal(err)
+			}
+			if header.APIVersion != APIVersion || header.Kind != Kind {
+				t.Errorf("header = %s %s, want %s %s", header.APIVersion, header.Kind, APIVersion, Kind)
+			}
+			var decoded ConformanceClaim
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, tt.claim) {
+				t.Errorf("round trip mismatch:\n got %+v\nwant %+v", decoded, tt.claim)
+			}
+		})
+	}
+}
+
+func TestWireDecodesLegacyClaims(t *testing.T) {
+	legacy := `{
+		"clamId": "legacy-1",
+		"timestamp": "2025-06-01T12:00:00Z",
+		"resourceRef": "pod-a",
+		"summary": "Resource 'pod-a' from OPA is allow.",
+		"catalogId": "TEST-CAT",
+		"controlId": "CAT.T01",
+		"configRevision": "abc123",
+		"assessment": {
+			"requirement_id": "CAT.T01.TR01",
+			"methods": [{"name": "OPA", "description": "allowed", "run": true, "result": {"status": "COMPLIANT"}}]
+		}
+	}`
+	var claim ConformanceClaim
+	if err := json.Unmarshal([]byte(legacy), &claim); err != nil {
+		t.Fatal(err)
+	}
+	if claim.ClaimID != "legacy-1" {
+		t.Errorf("claim ID = %q, want legacy-1", claim.ClaimID)
+	}
+	if !claim.Timestamp.Equal(testTime) {
+		t.Errorf("timestamp = %s, want %s", claim.Timestamp, testTime)
+	}
+	if claim.Assessment.RequirementID != "CAT.T01.TR01" {
+		t.Errorf("requirement = %q, want CAT.T01.TR01", claim.Assessment.RequirementID)
+	}
+	if len(claim.Assessment.Methods) != 1 || statusOf(claim) != StatusCompliant {
+		t.Errorf("methods = %+v, want one COMPLIANT OPA method", claim.Assessment.Methods)
+	}
+	if claim.RawEvidenceRef != "" || claim.TenantID() != "default" {
+		t.Errorf("legacy claim has evidence ref %q and tenant %q", claim.RawEvidenceRef, claim.TenantID())
+	}
+
+	// Re-encoding upgrades the legacy claim to the v1 wire format.
+	data, err := json.Marshal(claim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"apiVersion":"`+APIVersion+`"`) || !strings.Contains(string(data), `"claimId":"legacy-1"`) {
+		t.Errorf("re-encoded legacy claim is not v1: %s", data)
+	}
+}
+
+func TestWireRejectsUnknownVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unknown version", `{"apiVersion":"claims.shiny-journey.io/v2","kind":"ConformanceClaim"}`, "unsupported claim apiVersion"},
+		{"unknown kind", `{"apiVersion":"claims.shiny-journey.io/v1","kind":"Verdict"}`, "unsupported kind"},
+		{"not an object", `[]`, "cannot unmarshal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claim ConformanceClaim
+			err := json.Unmarshal([]byte(tt.data), &claim)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchemaMatchesWireFormat(t *testing.T) {
+	schema := Schema()
+	if schema.ID != SchemaID {
+		t.Errorf("schema ID = %s, want %s", schema.ID, SchemaID)
+	}
+	for _, property := range []string{"apiVersion", "kind", "claimId", "rawEvidenceRef", "assessment", "provenance"} {
+		if _, ok := schema.Properties.Get(property); !ok {
+			t.Errorf("schema has no %s property", property)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+// Package version reports the build version of the agent.
+package version
+
+import "runtime/debug"
+
+// version is set at build time with
+// -ldflags "-X github.com/jpower432/shiny-journey/processor/version.version=<version>".
+var version string
+
+// Get returns the build version, falling back to the module version or VCS revision
+// recorded by the Go toolchain.
+func Get() string {
+	if version != "" {
+		return version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return "devel"
+}